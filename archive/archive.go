@@ -0,0 +1,174 @@
+// Package archive locks organizations without deleting them.
+//
+// Archiving removes every member of an organization but its admins and saves
+// the removed memberships in a Record. Unarchiving adds them back with their
+// exact roles. The API cannot update organizations, so the record kept in a
+// Store is what tags an organization as archived.
+package archive
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// API is the part of VisualizationClient used to archive organizations
+type API interface {
+	GetOrganizationID(OrgID string) (client.Org, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+	CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error)
+	DeleteOrganizationUser(userID string, orgID string) (client.UserInOrganization, error)
+}
+
+// Record memberships removed by archiving an organization
+type Record struct {
+	OrgID        string                      `json:"orgID"`
+	Organization string                      `json:"organization"`
+	ArchivedAt   time.Time                   `json:"archivedAt"`
+	Members      []client.UserInOrganization `json:"members"`
+}
+
+func (r Record) copy() Record {
+	r.Members = append([]client.UserInOrganization{}, r.Members...)
+	return r
+}
+
+// Options tune what archiving removes
+type Options struct {
+	// PreserveLogins are never removed from organizations (e.g. "admin")
+	PreserveLogins []string
+}
+
+// Archiver archives and unarchives organizations
+type Archiver struct {
+	api   API
+	store Store
+	opts  Options
+	now   func() time.Time
+}
+
+// New returns an archiver working on api and keeping its records in store
+func New(api API, store Store, opts Options) *Archiver {
+	return &Archiver{api: api, store: store, opts: opts, now: time.Now}
+}
+
+type byUserID []client.UserInOrganization
+
+func (u byUserID) Len() int           { return len(u) }
+func (u byUserID) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u byUserID) Less(i, j int) bool { return u[i].UserID < u[j].UserID }
+
+// Archived reports whether the organization is archived
+func (a *Archiver) Archived(orgID string) (bool, error) {
+	_, err := a.store.Load(orgID)
+	if err == ErrNotArchived {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Archive removes the members of the organization that are neither admins nor
+// preserved, after saving them in its record.
+// Archiving an archived organization removes the members added since, and
+// resumes an interrupted archive; the record keeps the original roles.
+func (a *Archiver) Archive(orgID string) (record Record, err error) {
+	record, err = a.store.Load(orgID)
+	switch err {
+	case nil:
+	case ErrNotArchived:
+		org, err := a.api.GetOrganizationID(orgID)
+		if err != nil {
+			return Record{}, err
+		}
+		record = Record{OrgID: orgID, Organization: org.Name, ArchivedAt: a.now().UTC(),
+			Members: []client.UserInOrganization{}}
+	default:
+		return Record{}, err
+	}
+
+	members, err := a.api.GetOrganizationUsers(orgID)
+	if err != nil {
+		return Record{}, err
+	}
+
+	archived := map[string]bool{}
+	for _, member := range record.Members {
+		archived[member.UserID] = true
+	}
+	preserved := map[string]bool{}
+	for _, login := range a.opts.PreserveLogins {
+		preserved[login] = true
+	}
+
+	var remove []client.UserInOrganization
+	for _, member := range members {
+		if archived[member.UserID] {
+			remove = append(remove, member)
+			continue
+		}
+		if member.Role == client.RoleAdmin || preserved[member.Login] {
+			continue
+		}
+		member.Password = ""
+		record.Members = append(record.Members, member)
+		remove = append(remove, member)
+	}
+	sort.Sort(byUserID(record.Members))
+
+	// the record is saved first so the memberships can always be restored
+	err = a.store.Save(record)
+	if err != nil {
+		return Record{}, err
+	}
+
+	for _, member := range remove {
+		_, err = a.api.DeleteOrganizationUser(member.UserID, orgID)
+		if err != nil {
+			return record, fmt.Errorf("removing %s from %s: %v", member.Login, record.Organization, err)
+		}
+	}
+	return record, nil
+}
+
+// Unarchive restores the memberships and roles saved in the record of the
+// organization, then deletes the record.
+// Members present with another role get their archived role back; members
+// added while the organization was archived are left alone. An interrupted
+// unarchive keeps the record and can be run again.
+func (a *Archiver) Unarchive(orgID string) (record Record, err error) {
+	record, err = a.store.Load(orgID)
+	if err != nil {
+		return Record{}, err
+	}
+
+	members, err := a.api.GetOrganizationUsers(orgID)
+	if err != nil {
+		return Record{}, err
+	}
+	current := map[string]client.UserInOrganization{}
+	for _, member := range members {
+		current[member.UserID] = member
+	}
+
+	for _, member := range record.Members {
+		if present, ok := current[member.UserID]; ok {
+			if present.Role == member.Role {
+				continue
+			}
+			_, err = a.api.DeleteOrganizationUser(member.UserID, orgID)
+			if err != nil {
+				return record, fmt.Errorf("restoring the role of %s in %s: %v", member.Login, record.Organization, err)
+			}
+		}
+
+		_, err = a.api.CreateUserOrganization(orgID, client.UserInOrganization{OrgID: orgID,
+			UserID: member.UserID, Login: member.Login, Email: member.Email, Role: member.Role})
+		if err != nil {
+			return record, fmt.Errorf("restoring %s in %s: %v", member.Login, record.Organization, err)
+		}
+	}
+
+	return record, a.store.Delete(orgID)
+}
@@ -0,0 +1,114 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+var archivedAt = time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTenancy returns a server with the organization "tenant" (ID 1) whose
+// members are admin (2, Admin), alice (3, Editor), bob (4, Viewer) and
+// ops (5, Viewer)
+func newTenancy() *visualizationtest.Server {
+	s := visualizationtest.NewServer("token")
+	org := s.AddOrganization("tenant")
+	roles := []string{client.RoleAdmin, client.RoleEditor, client.RoleViewer, client.RoleViewer}
+	for i, login := range []string{"admin", "alice", "bob", "ops"} {
+		user := s.AddUser(client.User{Login: login, Email: login + "@example.com"})
+		s.AddMember(org.OrganizationID, user.UserID, roles[i])
+	}
+	return s
+}
+
+func newArchiver(s *visualizationtest.Server, store Store) *Archiver {
+	a := New(s.Client(), store, Options{PreserveLogins: []string{"ops"}})
+	a.now = func() time.Time { return archivedAt }
+	return a
+}
+
+func logins(members []client.UserInOrganization) (names []string) {
+	for _, member := range members {
+		names = append(names, member.Login+":"+member.Role)
+	}
+	return
+}
+
+func TestArchive(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+	store := NewMemoryStore()
+	a := newArchiver(s, store)
+	before := s.Members("1")
+
+	archived, err := a.Archived("1")
+	assert.Equal(t, nil, err)
+	assert.False(t, archived)
+
+	record, err := a.Archive("1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1", record.OrgID)
+	assert.Equal(t, "tenant", record.Organization)
+	assert.Equal(t, archivedAt, record.ArchivedAt)
+	assert.Equal(t, []string{"alice:Editor", "bob:Viewer"}, logins(record.Members))
+	assert.Equal(t, []string{"admin:Admin", "ops:Viewer"}, logins(s.Members("1")), "admins and preserved logins kept")
+
+	saved, err := store.Load("1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, record, saved)
+	archived, err = a.Archived("1")
+	assert.Equal(t, nil, err)
+	assert.True(t, archived)
+
+	// members added while archived are removed and recorded, roles kept
+	s.AddMember("1", "4", client.RoleEditor)
+	carol := s.AddUser(client.User{Login: "carol"})
+	s.AddMember("1", carol.UserID, client.RoleViewer)
+	record, err = a.Archive("1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"alice:Editor", "bob:Viewer", "carol:Viewer"}, logins(record.Members))
+	assert.Equal(t, []string{"admin:Admin", "ops:Viewer"}, logins(s.Members("1")))
+
+	// bob comes back with another role while archived
+	s.AddMember("1", "4", client.RoleAdmin)
+	record, err = a.Unarchive("1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tenant", record.Organization)
+	assert.Equal(t, append(before, client.UserInOrganization{OrgID: "1", UserID: carol.UserID, Login: "carol", Role: client.RoleViewer}),
+		s.Members("1"), "memberships and roles restored exactly")
+
+	_, err = store.Load("1")
+	assert.Equal(t, ErrNotArchived, err)
+	_, err = a.Unarchive("1")
+	assert.Equal(t, ErrNotArchived, err)
+}
+
+func TestArchiveErrors(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+	store := NewMemoryStore()
+	a := newArchiver(s, store)
+
+	_, err := a.Archive("42")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+	records, _ := store.List()
+	assert.Equal(t, []Record{}, records, "nothing recorded")
+
+	_, err = a.Archive("1")
+	assert.Equal(t, nil, err)
+
+	// alice was deleted while the organization was archived
+	for _, user := range s.Users() {
+		if user.Login == "alice" {
+			s.Client().DeleteUser(user.UserID)
+		}
+	}
+	_, err = a.Unarchive("1")
+	assert.Contains(t, err.Error(), "restoring alice in tenant")
+	_, err = store.Load("1")
+	assert.Equal(t, nil, err, "record kept to retry")
+}
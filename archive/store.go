@@ -0,0 +1,184 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNotArchived returned when an organization has no archive record
+var ErrNotArchived = errors.New("organization is not archived")
+
+// Store keeps the archive records, one per organization ID
+type Store interface {
+	// Load returns the record of the organization, ErrNotArchived when none
+	Load(orgID string) (Record, error)
+	Save(record Record) error
+	Delete(orgID string) error
+	// List returns every record ordered by organization ID
+	List() ([]Record, error)
+}
+
+// DirStore stores every record in a JSON file named after the organization
+// ID in a directory
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a store keeping the records in dir, created if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) path(orgID string) (string, error) {
+	if orgID == "" || strings.ContainsAny(orgID, `/\`) || orgID == "." || orgID == ".." {
+		return "", fmt.Errorf("invalid organization ID %q", orgID)
+	}
+	return filepath.Join(s.dir, orgID+".json"), nil
+}
+
+// Load returns the record of the organization, ErrNotArchived when none
+func (s *DirStore) Load(orgID string) (record Record, err error) {
+	path, err := s.path(orgID)
+	if err != nil {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Record{}, ErrNotArchived
+	}
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return Record{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return
+}
+
+// Save writes the record, replacing the file atomically
+func (s *DirStore) Save(record Record) error {
+	path, err := s.path(record.OrgID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(s.dir, ".record")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Delete removes the record of the organization
+func (s *DirStore) Delete(orgID string) error {
+	path, err := s.path(orgID)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrNotArchived
+	}
+	return err
+}
+
+// List returns every record ordered by organization ID
+func (s *DirStore) List() ([]Record, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	records := []Record{}
+	for _, path := range paths {
+		record, err := s.Load(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	sort.Sort(byOrgID(records))
+	return records, nil
+}
+
+// MemoryStore keeps the records in memory, for tests and short lived processes
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore returns an empty in memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+// Load returns the record of the organization, ErrNotArchived when none
+func (s *MemoryStore) Load(orgID string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[orgID]
+	if !ok {
+		return Record{}, ErrNotArchived
+	}
+	return record.copy(), nil
+}
+
+// Save stores the record
+func (s *MemoryStore) Save(record Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.OrgID] = record.copy()
+	return nil
+}
+
+// Delete removes the record of the organization
+func (s *MemoryStore) Delete(orgID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[orgID]; !ok {
+		return ErrNotArchived
+	}
+	delete(s.records, orgID)
+	return nil
+}
+
+// List returns every record ordered by organization ID
+func (s *MemoryStore) List() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := []Record{}
+	for _, record := range s.records {
+		records = append(records, record.copy())
+	}
+	sort.Sort(byOrgID(records))
+	return records, nil
+}
+
+type byOrgID []Record
+
+func (r byOrgID) Len() int           { return len(r) }
+func (r byOrgID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byOrgID) Less(i, j int) bool { return r[i].OrgID < r[j].OrgID }
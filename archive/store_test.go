@@ -0,0 +1,52 @@
+package archive
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dir)
+	dirStore, err := NewDirStore(dir)
+	assert.Equal(t, nil, err)
+
+	tests := []struct {
+		description string
+		store       Store
+	}{
+		{"directory", dirStore},
+		{"memory", NewMemoryStore()},
+	}
+	for _, testCase := range tests {
+		store := testCase.store
+		_, err := store.Load("1")
+		assert.Equal(t, ErrNotArchived, err, testCase.description)
+		assert.Equal(t, ErrNotArchived, store.Delete("1"), testCase.description)
+
+		first := Record{OrgID: "2", Organization: "beta", ArchivedAt: archivedAt,
+			Members: []client.UserInOrganization{{OrgID: "2", UserID: "3", Login: "alice", Role: client.RoleEditor}}}
+		second := Record{OrgID: "1", Organization: "alpha", ArchivedAt: archivedAt, Members: []client.UserInOrganization{}}
+		assert.Equal(t, nil, store.Save(first), testCase.description)
+		assert.Equal(t, nil, store.Save(second), testCase.description)
+
+		loaded, err := store.Load("2")
+		assert.Equal(t, nil, err, testCase.description)
+		assert.Equal(t, first, loaded, testCase.description)
+
+		records, err := store.List()
+		assert.Equal(t, nil, err, testCase.description)
+		assert.Equal(t, []Record{second, first}, records, testCase.description)
+
+		assert.Equal(t, nil, store.Delete("2"), testCase.description)
+		records, _ = store.List()
+		assert.Equal(t, []Record{second}, records, testCase.description)
+	}
+
+	assert.Equal(t, `invalid organization ID "../1"`, dirStore.Save(Record{OrgID: "../1"}).Error())
+}
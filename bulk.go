@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// BulkStatus outcome of an item of a bulk operation
+type BulkStatus string
+
+// Outcomes of the items of a bulk operation
+const (
+	// BulkSucceeded the item was applied
+	BulkSucceeded BulkStatus = "succeeded"
+	// BulkAlreadyExists the user was already in the organization
+	BulkAlreadyExists BulkStatus = "already exists"
+	// BulkNotFound the user was not in the organization
+	BulkNotFound BulkStatus = "not found"
+	// BulkFailed the item failed with Err
+	BulkFailed BulkStatus = "failed"
+)
+
+// BulkResult result of an item of a bulk operation
+type BulkResult struct {
+	User   UserInOrganization
+	Status BulkStatus
+	Err    error
+}
+
+// AddOrganizationUsers adds users to the organization, up to concurrency at
+// a time. It returns a result per user, in the order of users; a failed user
+// does not stop the others.
+func (v *VisualizationClient) AddOrganizationUsers(OrgID string, users []UserInOrganization, concurrency int) []BulkResult {
+	return v.AddOrganizationUsersContext(context.Background(), OrgID, users, concurrency)
+}
+
+// AddOrganizationUsersContext is AddOrganizationUsers within ctx
+func (v *VisualizationClient) AddOrganizationUsersContext(ctx context.Context, OrgID string, users []UserInOrganization, concurrency int) []BulkResult {
+	ctx, span := v.startSpan(ctx, "AddOrganizationUsers", Attribute{Key: AttributeOrgID, Value: OrgID})
+	defer span.End()
+
+	return v.bulk(ctx, users, concurrency, func(user UserInOrganization) BulkResult {
+		added, err := v.createUserOrganization(ctx, OrgID, user)
+		switch {
+		case err == nil:
+			return BulkResult{User: added, Status: BulkSucceeded}
+		case hasCode(err, "409"):
+			return BulkResult{User: user, Status: BulkAlreadyExists}
+		default:
+			return BulkResult{User: user, Status: BulkFailed, Err: err}
+		}
+	})
+}
+
+// RemoveOrganizationUsers removes the users with the given IDs from the
+// organization, up to concurrency at a time. It returns a result per user,
+// in the order of userIDs; a failed user does not stop the others.
+func (v *VisualizationClient) RemoveOrganizationUsers(orgID string, userIDs []string, concurrency int) []BulkResult {
+	return v.RemoveOrganizationUsersContext(context.Background(), orgID, userIDs, concurrency)
+}
+
+// RemoveOrganizationUsersContext is RemoveOrganizationUsers within ctx
+func (v *VisualizationClient) RemoveOrganizationUsersContext(ctx context.Context, orgID string, userIDs []string, concurrency int) []BulkResult {
+	ctx, span := v.startSpan(ctx, "RemoveOrganizationUsers", Attribute{Key: AttributeOrgID, Value: orgID})
+	defer span.End()
+
+	users := make([]UserInOrganization, len(userIDs))
+	for i, userID := range userIDs {
+		users[i] = UserInOrganization{OrgID: orgID, UserID: userID}
+	}
+	return v.bulk(ctx, users, concurrency, func(user UserInOrganization) BulkResult {
+		removed, err := v.deleteOrganizationUser(ctx, user.UserID, orgID)
+		switch {
+		case err == nil:
+			if removed.UserID == "" {
+				removed = user
+			}
+			return BulkResult{User: removed, Status: BulkSucceeded}
+		case hasCode(err, "404"):
+			return BulkResult{User: user, Status: BulkNotFound}
+		default:
+			return BulkResult{User: user, Status: BulkFailed, Err: err}
+		}
+	})
+}
+
+// bulk applies apply to users with at most concurrency calls in flight
+func (v *VisualizationClient) bulk(ctx context.Context, users []UserInOrganization, concurrency int, apply func(UserInOrganization) BulkResult) []BulkResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// authenticate once before the workers start
+	v.doRequest(ctx, false)
+
+	results := make([]BulkResult, len(users))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(users); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = apply(users[i])
+			}
+		}()
+	}
+	for i := range users {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// hasCode reports whether err is a VisualizationError with the HTTP status code
+func hasCode(err error, code string) bool {
+	e, ok := err.(VisualizationError)
+	return ok && e.code == code
+}
@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddOrganizationUsers(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	var users []client.UserInOrganization
+	for _, login := range []string{"alice", "bob", "carol", "dave"} {
+		user := s.AddUser(client.User{Login: login, Email: login + "@example.com"})
+		users = append(users, client.UserInOrganization{Login: user.Login, Role: client.RoleViewer})
+	}
+	s.AddMember(org.OrganizationID, "3", client.RoleAdmin)
+	users = append(users, client.UserInOrganization{Login: "mallory", Role: client.RoleViewer})
+
+	results := s.Client().AddOrganizationUsers(org.OrganizationID, users, 3)
+
+	var statuses []client.BulkStatus
+	for i, result := range results {
+		statuses = append(statuses, result.Status)
+		assert.Equal(t, users[i].Login, result.User.Login, "results in input order")
+	}
+	assert.Equal(t, []client.BulkStatus{
+		client.BulkSucceeded,
+		client.BulkAlreadyExists,
+		client.BulkSucceeded,
+		client.BulkSucceeded,
+		client.BulkFailed,
+	}, statuses)
+	assert.Equal(t, "2", results[0].User.UserID)
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", results[4].Err.Error())
+	assert.Equal(t, 4, len(s.Members(org.OrganizationID)))
+	assert.Equal(t, client.RoleAdmin, s.Members(org.OrganizationID)[1].Role, "existing member untouched")
+}
+
+func TestRemoveOrganizationUsers(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	alice := s.AddUser(client.User{Login: "alice"})
+	bob := s.AddUser(client.User{Login: "bob"})
+	s.AddMember(org.OrganizationID, alice.UserID, client.RoleViewer)
+	s.AddMember(org.OrganizationID, bob.UserID, client.RoleViewer)
+	s.InjectFault(visualizationtest.Fault{
+		Method: "DELETE",
+		Path:   "/admin/organizations/" + org.OrganizationID + "/users/" + bob.UserID,
+		Status: http.StatusInternalServerError,
+	})
+
+	results := s.Client().RemoveOrganizationUsers(org.OrganizationID, []string{alice.UserID, "42", bob.UserID}, 2)
+
+	assert.Equal(t, client.BulkSucceeded, results[0].Status)
+	assert.Equal(t, "alice", results[0].User.Login)
+	assert.Equal(t, client.BulkNotFound, results[1].Status)
+	assert.Equal(t, "42", results[1].User.UserID)
+	assert.Equal(t, client.BulkFailed, results[2].Status)
+	assert.NotNil(t, results[2].Err)
+	assert.Equal(t, []client.UserInOrganization{{OrgID: org.OrganizationID, UserID: bob.UserID, Login: "bob", Role: client.RoleViewer}}, s.Members(org.OrganizationID))
+}
+
+func TestBulkConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, authentications := 0, 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			mu.Lock()
+			authentications++
+			mu.Unlock()
+			fmt.Fprintf(w, `{"jwt":"jwt","token":{"expiresAt":"%s"}}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+			return
+		}
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	api, _ := client.NewVisualizationClient(ts.URL, http.Client{}, "token")
+	users := make([]client.UserInOrganization, 20)
+	for i := range users {
+		users[i] = client.UserInOrganization{Login: strings.Repeat("u", i+1), Role: client.RoleViewer}
+	}
+	results := api.AddOrganizationUsers("1", users, 4)
+
+	assert.Equal(t, 20, len(results))
+	assert.Equal(t, 4, maxInFlight)
+	assert.Equal(t, 1, authentications, "authenticated once")
+}
@@ -2,10 +2,13 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -22,106 +25,208 @@ func (e VisualizationError) Error() string {
 	return fmt.Sprintf("ERROR: %s", e.description)
 }
 
-// VisualizationClient client for Visualization
+// VisualizationClient client for Visualization, safe for concurrent use
 type VisualizationClient struct {
 	url            string
 	client         *http.Client
+	mu             sync.Mutex
 	token          AuthToken
 	JWT            string
 	openstackToken string
+	logger         Logger
+	retry          retryPolicy
+	metrics        Metrics
+	tracer         Tracer
+	validators     *validators
+	// userOrgsEndpoint whether the API serves the organizations of a user
+	userOrgsEndpoint int32
 }
 
 // NewVisualizationClient returns client with token
-func NewVisualizationClient(url string, client http.Client, openstackToken string) (*VisualizationClient, error) {
-	return &VisualizationClient{client: &client, url: url, openstackToken: openstackToken}, nil
-
+func NewVisualizationClient(url string, client http.Client, openstackToken string, options ...Option) (*VisualizationClient, error) {
+	v := &VisualizationClient{client: &client, url: url, openstackToken: openstackToken, metrics: noopMetrics{}, tracer: noopTracer{},
+		retry: retryPolicy{backoff: DefaultBackoff, maxBackoff: DefaultMaxBackoff}}
+	for _, option := range options {
+		option(v)
+	}
+	return v, nil
 }
 
 // reIssue this method reissues the token
-func (v *VisualizationClient) reIssue() error {
-	token, err := v.Authenticate()
+func (v *VisualizationClient) reIssue(ctx context.Context) error {
+	v.metrics.IncReauthentication()
+	token, err := v.authenticate(ctx)
 	if err != nil {
 		return err
 	}
+	v.mu.Lock()
+	v.token = token
 	v.JWT = token.JWT
+	v.mu.Unlock()
+	v.metrics.SetTokenExpiry(token.Token.ExpiresAt)
 	return err
 }
 
 // authorizeToken for token checking and reauth
-func (v *VisualizationClient) authorizeToken(withAuth bool) {
+func (v *VisualizationClient) authorizeToken(ctx context.Context, withAuth bool) {
 	if !withAuth {
 		// validate token
+		v.mu.Lock()
 		tokenExpires := v.token.Token.ExpiresAt.UnixNano() / 1000000
+		v.mu.Unlock()
 		now := time.Now().UnixNano() / 1000000
 		if tokenExpires < now {
-			v.reIssue()
+			v.reIssue(ctx)
 		}
 	}
 	return
 }
 
 // doRequest does the authorized request
-func (v *VisualizationClient) doRequest(withAuth bool) {
-	v.authorizeToken(withAuth)
+func (v *VisualizationClient) doRequest(ctx context.Context, withAuth bool) {
+	v.authorizeToken(ctx, withAuth)
 	return
 }
 
 // headerRequest adds header to Request
-func (v *VisualizationClient) headerRequest(request *http.Request, withAuth bool) *http.Request {
+func (v *VisualizationClient) headerRequest(ctx context.Context, request *http.Request, withAuth bool) *http.Request {
 	if withAuth {
 		request.Header.Add("X-OpenStack-Auth-Token", v.openstackToken)
 	} else {
-		if v.token == (AuthToken{}) {
-			v.reIssue()
+		v.mu.Lock()
+		empty := v.token == (AuthToken{})
+		v.mu.Unlock()
+		if empty {
+			v.reIssue(ctx)
 		}
+		v.mu.Lock()
 		bearer := fmt.Sprintf("Bearer %v", v.JWT)
+		v.mu.Unlock()
 		request.Header.Add("Authorization", bearer)
 	}
 	return request
 }
 
 // httpRequest handles the request to server.
-//It returns the response body and a error if something went wrong
-func (v *VisualizationClient) httpRequest(method string, url string, body io.Reader, withAuth bool) (result io.Reader, err error) {
-	v.doRequest(withAuth)
-	request, err := http.NewRequest(method, url, body)
-	request.Header.Set("Content-Type", "application/json")
-	request = v.headerRequest(request, withAuth)
+// It returns the response body and a error if something went wrong.
+// Failed attempts are retried as configured by WithRetries.
+func (v *VisualizationClient) httpRequest(ctx context.Context, operation string, method string, url string, body io.Reader, withAuth bool) (result io.Reader, err error) {
+	result, _, err = v.request(ctx, operation, method, url, body, withAuth)
+	return
+}
+
+// request is httpRequest also returning the status of the last attempt
+func (v *VisualizationClient) request(ctx context.Context, operation string, method string, url string, body io.Reader, withAuth bool) (result io.Reader, status int, err error) {
+	var payload []byte
+	if body != nil {
+		payload, err = ioutil.ReadAll(body)
+		if err != nil {
+			return
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, status, err = v.attempt(ctx, operation, method, url, payload, withAuth, attempt)
+		if err == nil || attempt >= v.retry.retries || !v.retry.retryable(method, status, withAuth) {
+			return
+		}
+		if sleep(ctx, v.retry.delay(attempt)) != nil {
+			return
+		}
+		v.metrics.IncRetry(operation)
+		if status == 401 {
+			v.reIssue(ctx)
+		}
+	}
+}
+
+// attempt sends the request once, in a span child of the operation in ctx.
+// It returns the response body, the response status and a error if something went wrong
+func (v *VisualizationClient) attempt(ctx context.Context, operation string, method string, url string, payload []byte, withAuth bool, attempt int) (result io.Reader, status int, err error) {
+	v.doRequest(ctx, withAuth)
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
 
-	response, err := v.client.Do(request)
 	var Message VisualizationError
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		Message.description = err.Error()
+		return result, 0, Message
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request = v.headerRequest(ctx, request, withAuth)
+	v.conditionalHeaders(ctx, request, url)
+
+	ctx, span := v.tracer.Start(ctx, "HTTP "+method,
+		Attribute{Key: AttributeMethod, Value: method},
+		Attribute{Key: AttributeURL, Value: url},
+		Attribute{Key: AttributeRetryCount, Value: attempt})
+	defer func() {
+		span.SetAttributes(Attribute{Key: AttributeStatusCode, Value: status})
+		endSpan(span, err)
+	}()
+	v.tracer.Inject(ctx, request.Header)
+
+	start := time.Now()
+	response, err := v.client.Do(request)
+	if err != nil {
+		v.metrics.ObserveRequest(operation, 0, time.Since(start))
+		v.logAttempt(request, payload, 0, nil, time.Since(start), attempt, err)
+		Message.description = err.Error()
+		return result, 0, Message
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	v.metrics.ObserveRequest(operation, response.StatusCode, time.Since(start))
+	v.logAttempt(request, payload, response.StatusCode, data, time.Since(start), attempt, err)
 	if err != nil {
 		Message.description = err.Error()
-		return result, Message
+		return result, 0, Message
 	}
 
-	if response.StatusCode != 200 {
-		dec := json.NewDecoder(response.Body)
+	status = response.StatusCode
+	cached, err := v.conditionalResponse(ctx, method, url, response, data)
+	if err != nil {
+		return result, status, err
+	}
+	if cached != nil {
+		return cached, status, nil
+	}
+	if status != 200 {
+		dec := json.NewDecoder(bytes.NewReader(data))
 		err = dec.Decode(&Message)
 		if err != nil {
 			return
 		}
-		switch response.StatusCode {
+		switch status {
 		case 409:
 			Message.code = "409"
 			Message.message = "Already Exists"
 			Message.description = "Provided Details to create exists"
-			return result, Message
+			return result, status, Message
 		case 404:
 			Message.code = "404"
 			Message.message = "ID not found"
 			Message.description = "Provided ID to Delete/Get was not found"
-			return result, Message
+			return result, status, Message
 		case 401:
 			Message.code = "401"
 			Message.message = "UnAuthorized"
 			Message.description = "request not authorized"
-			return result, Message
+			return result, status, Message
+		case 412:
+			Message.code = "412"
+			Message.message = "Precondition Failed"
+			Message.description = "Provided resource was modified since it was read"
+			return result, status, Message
 		}
 
-		return result, Message
+		return result, status, Message
 	}
-	result = response.Body
+	result = bytes.NewReader(data)
 	return
 }
 
@@ -138,6 +243,13 @@ type Token struct {
 	IsAdmin        bool      `json:"isAdmin"`
 }
 
+// Roles a user can have in an organization
+const (
+	RoleViewer = "Viewer"
+	RoleEditor = "Editor"
+	RoleAdmin  = "Admin"
+)
+
 // UserInOrganization Get Users in organization
 type UserInOrganization struct {
 	OrgID    string `json:"orgID"`
@@ -166,8 +278,20 @@ type User struct {
 
 // Authenticate gets a openstack token
 func (v *VisualizationClient) Authenticate() (token AuthToken, err error) {
+	return v.AuthenticateContext(context.Background())
+}
+
+// AuthenticateContext is Authenticate within ctx
+func (v *VisualizationClient) AuthenticateContext(ctx context.Context) (token AuthToken, err error) {
+	return v.authenticate(ctx)
+}
+
+func (v *VisualizationClient) authenticate(ctx context.Context) (token AuthToken, err error) {
+	ctx, span := v.startSpan(ctx, "Authenticate")
+	defer func() { endSpan(span, err) }()
+
 	reqURL := v.url + "/auth/openstack"
-	response, err := v.httpRequest("POST", reqURL, nil, true)
+	response, err := v.httpRequest(ctx, "Authenticate", "POST", reqURL, nil, true)
 
 	if err != nil {
 		return
@@ -184,21 +308,48 @@ func (v *VisualizationClient) Authenticate() (token AuthToken, err error) {
 
 // GetUsers returns list of users
 func (v *VisualizationClient) GetUsers() (user []User, err error) {
+	return v.GetUsersContext(context.Background())
+}
+
+// GetUsersContext is GetUsers within ctx
+func (v *VisualizationClient) GetUsersContext(ctx context.Context) (user []User, err error) {
+	user, _, err = v.getUsers(ctx)
+	return
+}
+
+func (v *VisualizationClient) getUsers(ctx context.Context) (user []User, modified bool, err error) {
+	ctx, span := v.startSpan(ctx, "GetUsers")
+	defer func() { endSpan(span, err) }()
+
 	reqURL := v.url + "/admin/users"
-	response, err := v.httpRequest("GET", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "GetUsers", "GET", reqURL, nil, false)
 	if err != nil {
-		return []User{}, err
+		return []User{}, false, err
 	}
 
+	_, cached := response.(*notModified)
 	dec := json.NewDecoder(response)
 	err = dec.Decode(&user)
+	modified = !cached
 
 	return
 }
 
 // GetUserName returns user by Name
 func (v *VisualizationClient) GetUserName(name string) (user User, err error) {
-	users, err := v.GetUsers()
+	return v.GetUserNameContext(context.Background(), name)
+}
+
+// GetUserNameContext is GetUserName within ctx
+func (v *VisualizationClient) GetUserNameContext(ctx context.Context, name string) (user User, err error) {
+	return v.getUserName(ctx, name)
+}
+
+func (v *VisualizationClient) getUserName(ctx context.Context, name string) (user User, err error) {
+	ctx, span := v.startSpan(ctx, "GetUserName")
+	defer func() { endSpan(span, err) }()
+
+	users, _, err := v.getUsers(ctx)
 	if err != nil {
 		return
 	}
@@ -208,13 +359,26 @@ func (v *VisualizationClient) GetUserName(name string) (user User, err error) {
 			user = elem
 		}
 	}
+	span.SetAttributes(Attribute{Key: AttributeUserID, Value: user.UserID})
 	return
 }
 
 // GetUserID Get User by ID
 func (v *VisualizationClient) GetUserID(ID string) (user User, err error) {
+	return v.GetUserIDContext(context.Background(), ID)
+}
+
+// GetUserIDContext is GetUserID within ctx
+func (v *VisualizationClient) GetUserIDContext(ctx context.Context, ID string) (user User, err error) {
+	return v.getUserID(ctx, ID)
+}
+
+func (v *VisualizationClient) getUserID(ctx context.Context, ID string) (user User, err error) {
+	ctx, span := v.startSpan(ctx, "GetUserID", Attribute{Key: AttributeUserID, Value: ID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/users/%s", v.url, ID)
-	response, err := v.httpRequest("GET", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "GetUserID", "GET", reqURL, nil, false)
 	if err != nil {
 		return
 	}
@@ -227,31 +391,59 @@ func (v *VisualizationClient) GetUserID(ID string) (user User, err error) {
 
 // CreateUser creates a user
 func (v *VisualizationClient) CreateUser(user User) (userDetails User, err error) {
-	reqURL := v.url + "/admin/users"
-	jsonStr, err := json.Marshal(user)
-	if err != nil {
-		return
-	}
+	return v.CreateUserContext(context.Background(), user)
+}
 
-	_, err = v.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr), false)
+// CreateUserContext is CreateUser within ctx
+func (v *VisualizationClient) CreateUserContext(ctx context.Context, user User) (userDetails User, err error) {
+	ctx, span := v.startSpan(ctx, "CreateUser")
+	defer func() { endSpan(span, err) }()
+
+	err = v.postUser(ctx, user)
 	if err != nil {
 		return
 	}
 
 	// Get user details by name
-	userDetails, err = v.GetUserName(user.Name)
+	userDetails, err = v.getUserName(ctx, user.Name)
 	if err != nil {
 		return
 	}
+	span.SetAttributes(Attribute{Key: AttributeUserID, Value: userDetails.UserID})
 
 	return
 }
 
+// postUser sends the creation of a user
+func (v *VisualizationClient) postUser(ctx context.Context, user User) error {
+	err := user.Validate()
+	if err != nil {
+		return err
+	}
+
+	reqURL := v.url + "/admin/users"
+	jsonStr, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	_, err = v.httpRequest(ctx, "CreateUser", "POST", reqURL, bytes.NewBuffer(jsonStr), false)
+	return err
+}
+
 // DeleteUser Delete the user with given id
 func (v *VisualizationClient) DeleteUser(ID string) (user User, err error) {
+	return v.DeleteUserContext(context.Background(), ID)
+}
+
+// DeleteUserContext is DeleteUser within ctx
+func (v *VisualizationClient) DeleteUserContext(ctx context.Context, ID string) (user User, err error) {
+	ctx, span := v.startSpan(ctx, "DeleteUser", Attribute{Key: AttributeUserID, Value: ID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/users/%s", v.url, ID)
 
-	response, err := v.httpRequest("DELETE", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "DeleteUser", "DELETE", reqURL, nil, false)
 	if err != nil {
 		return
 	}
@@ -263,21 +455,48 @@ func (v *VisualizationClient) DeleteUser(ID string) (user User, err error) {
 
 // GetOrganizations returns list of organizations
 func (v *VisualizationClient) GetOrganizations() (org []Org, err error) {
+	return v.GetOrganizationsContext(context.Background())
+}
+
+// GetOrganizationsContext is GetOrganizations within ctx
+func (v *VisualizationClient) GetOrganizationsContext(ctx context.Context) (org []Org, err error) {
+	org, _, err = v.getOrganizations(ctx)
+	return
+}
+
+func (v *VisualizationClient) getOrganizations(ctx context.Context) (org []Org, modified bool, err error) {
+	ctx, span := v.startSpan(ctx, "GetOrganizations")
+	defer func() { endSpan(span, err) }()
+
 	reqURL := v.url + "/admin/organizations"
-	response, err := v.httpRequest("GET", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "GetOrganizations", "GET", reqURL, nil, false)
 	if err != nil {
-		return []Org{}, err
+		return []Org{}, false, err
 	}
 
+	_, cached := response.(*notModified)
 	dec := json.NewDecoder(response)
 	err = dec.Decode(&org)
+	modified = !cached
 
 	return
 }
 
 // GetOrganizationName returns Organization by Name
 func (v *VisualizationClient) GetOrganizationName(name string) (org Org, err error) {
-	orgs, err := v.GetOrganizations()
+	return v.GetOrganizationNameContext(context.Background(), name)
+}
+
+// GetOrganizationNameContext is GetOrganizationName within ctx
+func (v *VisualizationClient) GetOrganizationNameContext(ctx context.Context, name string) (org Org, err error) {
+	return v.getOrganizationName(ctx, name)
+}
+
+func (v *VisualizationClient) getOrganizationName(ctx context.Context, name string) (org Org, err error) {
+	ctx, span := v.startSpan(ctx, "GetOrganizationName")
+	defer func() { endSpan(span, err) }()
+
+	orgs, _, err := v.getOrganizations(ctx)
 	if err != nil {
 		return
 	}
@@ -287,13 +506,22 @@ func (v *VisualizationClient) GetOrganizationName(name string) (org Org, err err
 			org = elem
 		}
 	}
+	span.SetAttributes(Attribute{Key: AttributeOrgID, Value: org.OrganizationID})
 	return
 }
 
 // GetOrganizationID Get Org by ID
 func (v *VisualizationClient) GetOrganizationID(OrgID string) (org Org, err error) {
+	return v.GetOrganizationIDContext(context.Background(), OrgID)
+}
+
+// GetOrganizationIDContext is GetOrganizationID within ctx
+func (v *VisualizationClient) GetOrganizationIDContext(ctx context.Context, OrgID string) (org Org, err error) {
+	ctx, span := v.startSpan(ctx, "GetOrganizationID", Attribute{Key: AttributeOrgID, Value: OrgID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/organizations/%s", v.url, OrgID)
-	response, err := v.httpRequest("GET", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "GetOrganizationID", "GET", reqURL, nil, false)
 	if err != nil {
 		return
 	}
@@ -306,9 +534,17 @@ func (v *VisualizationClient) GetOrganizationID(OrgID string) (org Org, err erro
 
 // DeleteOrganization Delete the organization with given id
 func (v *VisualizationClient) DeleteOrganization(ID string) (org Org, err error) {
+	return v.DeleteOrganizationContext(context.Background(), ID)
+}
+
+// DeleteOrganizationContext is DeleteOrganization within ctx
+func (v *VisualizationClient) DeleteOrganizationContext(ctx context.Context, ID string) (org Org, err error) {
+	ctx, span := v.startSpan(ctx, "DeleteOrganization", Attribute{Key: AttributeOrgID, Value: ID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/organizations/%s", v.url, ID)
 
-	response, err := v.httpRequest("DELETE", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "DeleteOrganization", "DELETE", reqURL, nil, false)
 	if err != nil {
 		return
 	}
@@ -321,41 +557,83 @@ func (v *VisualizationClient) DeleteOrganization(ID string) (org Org, err error)
 
 // CreateOrganization creates a organization
 func (v *VisualizationClient) CreateOrganization(org Org) (orgs Org, err error) {
+	return v.CreateOrganizationContext(context.Background(), org)
+}
+
+// CreateOrganizationContext is CreateOrganization within ctx
+func (v *VisualizationClient) CreateOrganizationContext(ctx context.Context, org Org) (orgs Org, err error) {
+	return v.createOrganization(ctx, org)
+}
+
+func (v *VisualizationClient) createOrganization(ctx context.Context, org Org) (orgs Org, err error) {
+	ctx, span := v.startSpan(ctx, "CreateOrganization")
+	defer func() { endSpan(span, err) }()
+
+	err = org.Validate()
+	if err != nil {
+		return
+	}
+
 	reqURL := v.url + "/admin/organizations"
 	jsonStr, err := json.Marshal(org)
 	if err != nil {
 		return
 	}
 
-	_, err = v.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr), false)
+	_, err = v.httpRequest(ctx, "CreateOrganization", "POST", reqURL, bytes.NewBuffer(jsonStr), false)
 	if err != nil {
 		return
 	}
 
-	orgs, err = v.GetOrganizationName(org.Name)
+	orgs, err = v.getOrganizationName(ctx, org.Name)
 	if err != nil {
 		return
 	}
+	span.SetAttributes(Attribute{Key: AttributeOrgID, Value: orgs.OrganizationID})
 
 	return
 }
 
 // GetOrganizationUsers gets Users in Organisation
 func (v *VisualizationClient) GetOrganizationUsers(ID string) (org []UserInOrganization, err error) {
+	return v.GetOrganizationUsersContext(context.Background(), ID)
+}
+
+// GetOrganizationUsersContext is GetOrganizationUsers within ctx
+func (v *VisualizationClient) GetOrganizationUsersContext(ctx context.Context, ID string) (org []UserInOrganization, err error) {
+	org, _, err = v.getOrganizationUsers(ctx, ID)
+	return
+}
+
+func (v *VisualizationClient) getOrganizationUsers(ctx context.Context, ID string) (org []UserInOrganization, modified bool, err error) {
+	ctx, span := v.startSpan(ctx, "GetOrganizationUsers", Attribute{Key: AttributeOrgID, Value: ID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/organizations/%s/users", v.url, ID)
-	response, err := v.httpRequest("GET", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "GetOrganizationUsers", "GET", reqURL, nil, false)
 	if err != nil {
-		return []UserInOrganization{}, err
+		return []UserInOrganization{}, false, err
 	}
 
+	_, cached := response.(*notModified)
 	dec := json.NewDecoder(response)
 	err = dec.Decode(&org)
+	modified = !cached
 	return
 }
 
 // GetOrganizationUserID gets User details in Organisation by ID
 func (v *VisualizationClient) GetOrganizationUserID(ID string, userID string) (user UserInOrganization, err error) {
-	users, err := v.GetOrganizationUsers(ID)
+	return v.GetOrganizationUserIDContext(context.Background(), ID, userID)
+}
+
+// GetOrganizationUserIDContext is GetOrganizationUserID within ctx
+func (v *VisualizationClient) GetOrganizationUserIDContext(ctx context.Context, ID string, userID string) (user UserInOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "GetOrganizationUserID",
+		Attribute{Key: AttributeOrgID, Value: ID}, Attribute{Key: AttributeUserID, Value: userID})
+	defer func() { endSpan(span, err) }()
+
+	users, _, err := v.getOrganizationUsers(ctx, ID)
 	if err != nil {
 		return
 	}
@@ -370,9 +648,22 @@ func (v *VisualizationClient) GetOrganizationUserID(ID string, userID string) (u
 
 // DeleteOrganizationUser Delete User in Organisation
 func (v *VisualizationClient) DeleteOrganizationUser(userID string, orgID string) (org UserInOrganization, err error) {
+	return v.DeleteOrganizationUserContext(context.Background(), userID, orgID)
+}
+
+// DeleteOrganizationUserContext is DeleteOrganizationUser within ctx
+func (v *VisualizationClient) DeleteOrganizationUserContext(ctx context.Context, userID string, orgID string) (org UserInOrganization, err error) {
+	return v.deleteOrganizationUser(ctx, userID, orgID)
+}
+
+func (v *VisualizationClient) deleteOrganizationUser(ctx context.Context, userID string, orgID string) (org UserInOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "DeleteOrganizationUser",
+		Attribute{Key: AttributeOrgID, Value: orgID}, Attribute{Key: AttributeUserID, Value: userID})
+	defer func() { endSpan(span, err) }()
+
 	reqURL := fmt.Sprintf("%s/admin/organizations/%s/users/%s", v.url, orgID, userID)
 
-	response, err := v.httpRequest("DELETE", reqURL, nil, false)
+	response, err := v.httpRequest(ctx, "DeleteOrganizationUser", "DELETE", reqURL, nil, false)
 	if err != nil {
 		return
 	}
@@ -385,13 +676,31 @@ func (v *VisualizationClient) DeleteOrganizationUser(userID string, orgID string
 
 // CreateUserOrganization Add User in Organisation
 func (v *VisualizationClient) CreateUserOrganization(OrgID string, user UserInOrganization) (org UserInOrganization, err error) {
+	return v.CreateUserOrganizationContext(context.Background(), OrgID, user)
+}
+
+// CreateUserOrganizationContext is CreateUserOrganization within ctx
+func (v *VisualizationClient) CreateUserOrganizationContext(ctx context.Context, OrgID string, user UserInOrganization) (org UserInOrganization, err error) {
+	return v.createUserOrganization(ctx, OrgID, user)
+}
+
+func (v *VisualizationClient) createUserOrganization(ctx context.Context, OrgID string, user UserInOrganization) (org UserInOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "CreateUserOrganization",
+		Attribute{Key: AttributeOrgID, Value: OrgID}, Attribute{Key: AttributeUserID, Value: user.UserID})
+	defer func() { endSpan(span, err) }()
+
+	err = user.Validate()
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+
 	reqURL := fmt.Sprintf("%s/admin/organizations/%s/users", v.url, OrgID)
 	jsonStr, err := json.Marshal(user)
 	if err != nil {
 		return UserInOrganization{}, err
 	}
 
-	response, err := v.httpRequest("POST", reqURL, bytes.NewBuffer(jsonStr), false)
+	response, err := v.httpRequest(ctx, "CreateUserOrganization", "POST", reqURL, bytes.NewBuffer(jsonStr), false)
 	if err != nil {
 		return UserInOrganization{}, err
 	}
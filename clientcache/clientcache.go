@@ -0,0 +1,382 @@
+// Package clientcache provides a read-through cache of a client.Client.
+//
+// The lists of users, organizations and members are fetched once and kept
+// for a TTL; lookups by ID, name or login are answered from them:
+//
+//	api := clientcache.New(visualization, time.Minute)
+//	for _, name := range names {
+//		org, err := api.GetOrganizationName(name)
+//		...
+//	}
+//
+// The Create* and Delete* calls invalidate the lists they change. Changes
+// made by other clients are seen once the TTL expires or after Invalidate.
+package clientcache
+
+import (
+	"sync"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// users cached list of users
+type users struct {
+	fetched time.Time
+	list    []client.User
+	byID    map[string]client.User
+	byName  map[string]client.User
+	byLogin map[string]client.User
+}
+
+// organizations cached list of organizations
+type organizations struct {
+	fetched time.Time
+	list    []client.Org
+	byID    map[string]client.Org
+	byName  map[string]client.Org
+}
+
+// members cached users of an organization
+type members struct {
+	fetched time.Time
+	list    []client.UserInOrganization
+}
+
+// Client caches the reads of a client.Client, safe for concurrent use
+type Client struct {
+	api client.Client
+	ttl time.Duration
+	now func() time.Time
+
+	mu            sync.Mutex
+	users         *users
+	organizations *organizations
+	members       map[string]*members
+}
+
+var _ client.Client = (*Client)(nil)
+
+// New returns a cache of api whose entries expire after ttl
+func New(api client.Client, ttl time.Duration) *Client {
+	return &Client{api: api, ttl: ttl, now: time.Now, members: map[string]*members{}}
+}
+
+// Invalidate drops every cached entry
+func (c *Client) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = nil
+	c.organizations = nil
+	c.members = map[string]*members{}
+}
+
+// fresh reports whether an entry fetched at fetched is still valid
+func (c *Client) fresh(fetched time.Time) bool {
+	return c.now().Sub(fetched) < c.ttl
+}
+
+// cachedUsers returns the users, fetching them when needed.
+// It must be called with c.mu held.
+func (c *Client) cachedUsers() (*users, error) {
+	if c.users != nil && c.fresh(c.users.fetched) {
+		return c.users, nil
+	}
+	list, err := c.api.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	cached := &users{
+		fetched: c.now(),
+		list:    list,
+		byID:    map[string]client.User{},
+		byName:  map[string]client.User{},
+		byLogin: map[string]client.User{},
+	}
+	for _, user := range list {
+		cached.byID[user.UserID] = user
+		cached.byName[user.Name] = user
+		cached.byLogin[user.Login] = user
+	}
+	c.users = cached
+	return cached, nil
+}
+
+// cachedOrganizations returns the organizations, fetching them when needed.
+// It must be called with c.mu held.
+func (c *Client) cachedOrganizations() (*organizations, error) {
+	if c.organizations != nil && c.fresh(c.organizations.fetched) {
+		return c.organizations, nil
+	}
+	list, err := c.api.GetOrganizations()
+	if err != nil {
+		return nil, err
+	}
+	cached := &organizations{
+		fetched: c.now(),
+		list:    list,
+		byID:    map[string]client.Org{},
+		byName:  map[string]client.Org{},
+	}
+	for _, org := range list {
+		cached.byID[org.OrganizationID] = org
+		cached.byName[org.Name] = org
+	}
+	c.organizations = cached
+	return cached, nil
+}
+
+// cachedMembers returns the users of organization ID, fetching them when needed.
+// It must be called with c.mu held.
+func (c *Client) cachedMembers(ID string) (*members, error) {
+	if cached, ok := c.members[ID]; ok && c.fresh(cached.fetched) {
+		return cached, nil
+	}
+	list, err := c.api.GetOrganizationUsers(ID)
+	if err != nil {
+		return nil, err
+	}
+	cached := &members{fetched: c.now(), list: list}
+	c.members[ID] = cached
+	return cached, nil
+}
+
+// Authenticate is not cached
+func (c *Client) Authenticate() (client.AuthToken, error) {
+	return c.api.Authenticate()
+}
+
+// GetUsers returns list of users
+func (c *Client) GetUsers() ([]client.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedUsers()
+	if err != nil {
+		return []client.User{}, err
+	}
+	return append([]client.User{}, cached.list...), nil
+}
+
+// GetUserName returns user by Name
+func (c *Client) GetUserName(name string) (client.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedUsers()
+	if err != nil {
+		return client.User{}, err
+	}
+	return cached.byName[name], nil
+}
+
+// GetUserLogin returns user by Login
+func (c *Client) GetUserLogin(login string) (client.User, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedUsers()
+	if err != nil {
+		return client.User{}, err
+	}
+	return cached.byLogin[login], nil
+}
+
+// GetUserID Get User by ID, asking the API for users missing in the cache
+func (c *Client) GetUserID(ID string) (client.User, error) {
+	c.mu.Lock()
+	cached, err := c.cachedUsers()
+	c.mu.Unlock()
+	if err == nil {
+		if user, ok := cached.byID[ID]; ok {
+			return user, nil
+		}
+	}
+	return c.api.GetUserID(ID)
+}
+
+// CreateUser creates a user and invalidates the users
+func (c *Client) CreateUser(user client.User) (client.User, error) {
+	created, err := c.api.CreateUser(user)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = nil
+	return created, err
+}
+
+// DeleteUser deletes a user and invalidates the users and the members
+func (c *Client) DeleteUser(ID string) (client.User, error) {
+	deleted, err := c.api.DeleteUser(ID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = nil
+	c.members = map[string]*members{}
+	return deleted, err
+}
+
+// GetOrganizations returns list of organizations
+func (c *Client) GetOrganizations() ([]client.Org, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedOrganizations()
+	if err != nil {
+		return []client.Org{}, err
+	}
+	return append([]client.Org{}, cached.list...), nil
+}
+
+// GetOrganizationName returns Organization by Name
+func (c *Client) GetOrganizationName(name string) (client.Org, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedOrganizations()
+	if err != nil {
+		return client.Org{}, err
+	}
+	return cached.byName[name], nil
+}
+
+// GetOrganizationID Get Org by ID, asking the API for organizations missing in the cache
+func (c *Client) GetOrganizationID(OrgID string) (client.Org, error) {
+	c.mu.Lock()
+	cached, err := c.cachedOrganizations()
+	c.mu.Unlock()
+	if err == nil {
+		if org, ok := cached.byID[OrgID]; ok {
+			return org, nil
+		}
+	}
+	return c.api.GetOrganizationID(OrgID)
+}
+
+// CreateOrganization creates a organization and invalidates the organizations
+func (c *Client) CreateOrganization(org client.Org) (client.Org, error) {
+	created, err := c.api.CreateOrganization(org)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.organizations = nil
+	return created, err
+}
+
+// DeleteOrganization deletes a organization and invalidates the organizations
+// and its members
+func (c *Client) DeleteOrganization(ID string) (client.Org, error) {
+	deleted, err := c.api.DeleteOrganization(ID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.organizations = nil
+	delete(c.members, ID)
+	return deleted, err
+}
+
+// GetOrganizationUsers gets Users in Organisation
+func (c *Client) GetOrganizationUsers(ID string) ([]client.UserInOrganization, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedMembers(ID)
+	if err != nil {
+		return []client.UserInOrganization{}, err
+	}
+	return append([]client.UserInOrganization{}, cached.list...), nil
+}
+
+// GetOrganizationUserID gets User details in Organisation by ID
+func (c *Client) GetOrganizationUserID(ID string, userID string) (user client.UserInOrganization, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, err := c.cachedMembers(ID)
+	if err != nil {
+		return
+	}
+	for _, elem := range cached.list {
+		if elem.UserID == userID {
+			user = elem
+		}
+	}
+	return
+}
+
+// CreateUserOrganization adds a user to a organization and invalidates its members
+func (c *Client) CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error) {
+	created, err := c.api.CreateUserOrganization(OrgID, user)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, OrgID)
+	return created, err
+}
+
+// DeleteOrganizationUser removes a user from a organization and invalidates its members
+func (c *Client) DeleteOrganizationUser(userID string, orgID string) (client.UserInOrganization, error) {
+	deleted, err := c.api.DeleteOrganizationUser(userID, orgID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, orgID)
+	return deleted, err
+}
+
+// GetUserOrganizations is not cached
+func (c *Client) GetUserOrganizations(userID string) ([]client.UserOrganization, error) {
+	return c.api.GetUserOrganizations(userID)
+}
+
+// AddOrganizationUsers adds users to a organization and invalidates its members
+func (c *Client) AddOrganizationUsers(OrgID string, users []client.UserInOrganization, concurrency int) []client.BulkResult {
+	results := c.api.AddOrganizationUsers(OrgID, users, concurrency)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, OrgID)
+	return results
+}
+
+// RemoveOrganizationUsers removes users from a organization and invalidates its members
+func (c *Client) RemoveOrganizationUsers(orgID string, userIDs []string, concurrency int) []client.BulkResult {
+	results := c.api.RemoveOrganizationUsers(orgID, userIDs, concurrency)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, orgID)
+	return results
+}
+
+// CopyOrganizationUser copies a user to a organization and invalidates its members
+func (c *Client) CopyOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (client.UserInOrganization, error) {
+	copied, err := c.api.CopyOrganizationUser(userID, fromOrgID, toOrgID, mapRole)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, toOrgID)
+	return copied, err
+}
+
+// MoveOrganizationUser moves a user between organizations and invalidates
+// the members of both
+func (c *Client) MoveOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (client.UserInOrganization, error) {
+	moved, err := c.api.MoveOrganizationUser(userID, fromOrgID, toOrgID, mapRole)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, fromOrgID)
+	delete(c.members, toOrgID)
+	return moved, err
+}
+
+// EnsureUser ensures a user exists and invalidates the users
+func (c *Client) EnsureUser(user client.User) (client.User, bool, error) {
+	existing, changed, err := c.api.EnsureUser(user)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users = nil
+	return existing, changed, err
+}
+
+// EnsureOrganization ensures a organization exists and invalidates the organizations
+func (c *Client) EnsureOrganization(org client.Org) (client.Org, bool, error) {
+	existing, changed, err := c.api.EnsureOrganization(org)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.organizations = nil
+	return existing, changed, err
+}
+
+// EnsureOrganizationUser ensures a user is in a organization and invalidates its members
+func (c *Client) EnsureOrganizationUser(OrgID string, user client.UserInOrganization) (client.UserInOrganization, bool, error) {
+	member, changed, err := c.api.EnsureOrganizationUser(OrgID, user)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.members, OrgID)
+	return member, changed, err
+}
@@ -0,0 +1,248 @@
+package clientcache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/clientmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMock() *clientmock.Client {
+	return &clientmock.Client{
+		GetUsersFunc: func() ([]client.User, error) {
+			return []client.User{
+				{UserID: "1", Name: "Alice", Login: "alice"},
+				{UserID: "2", Name: "Bob", Login: "bob"},
+			}, nil
+		},
+		GetOrganizationsFunc: func() ([]client.Org, error) {
+			return []client.Org{{OrganizationID: "1", Name: "main"}, {OrganizationID: "2", Name: "team"}}, nil
+		},
+		GetOrganizationUsersFunc: func(ID string) ([]client.UserInOrganization, error) {
+			return []client.UserInOrganization{{OrgID: ID, UserID: "1", Login: "alice", Role: "Admin"}}, nil
+		},
+		GetUserIDFunc: func(ID string) (client.User, error) {
+			return client.User{UserID: ID}, nil
+		},
+	}
+}
+
+func TestLookups(t *testing.T) {
+	mock := newMock()
+	cache := New(mock, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		user, err := cache.GetUserName("Bob")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "2", user.UserID)
+
+		user, _ = cache.GetUserLogin("alice")
+		assert.Equal(t, "1", user.UserID)
+
+		user, _ = cache.GetUserID("2")
+		assert.Equal(t, "bob", user.Login)
+
+		org, _ := cache.GetOrganizationName("team")
+		assert.Equal(t, "2", org.OrganizationID)
+
+		org, _ = cache.GetOrganizationID("1")
+		assert.Equal(t, "main", org.Name)
+
+		member, _ := cache.GetOrganizationUserID("2", "1")
+		assert.Equal(t, "Admin", member.Role)
+	}
+
+	assert.Equal(t, 1, len(mock.CallsTo("GetUsers")))
+	assert.Equal(t, 0, len(mock.CallsTo("GetUserID")))
+	assert.Equal(t, 1, len(mock.CallsTo("GetOrganizations")))
+	assert.Equal(t, 1, len(mock.CallsTo("GetOrganizationUsers")))
+
+	user, _ := cache.GetUserID("9")
+	assert.Equal(t, "9", user.UserID, "missing IDs asked to the API")
+	assert.Equal(t, 1, len(mock.CallsTo("GetUserID")))
+
+	user, err := cache.GetUserName("nobody")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, client.User{}, user)
+}
+
+func TestExpiry(t *testing.T) {
+	mock := newMock()
+	cache := New(mock, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.GetOrganizations()
+	now = now.Add(59 * time.Second)
+	cache.GetOrganizations()
+	assert.Equal(t, 1, len(mock.CallsTo("GetOrganizations")))
+
+	now = now.Add(time.Second)
+	cache.GetOrganizations()
+	assert.Equal(t, 2, len(mock.CallsTo("GetOrganizations")))
+
+	cache.Invalidate()
+	cache.GetOrganizations()
+	assert.Equal(t, 3, len(mock.CallsTo("GetOrganizations")))
+}
+
+func TestInvalidation(t *testing.T) {
+	tests := []struct {
+		description string
+		call        func(cache *Client)
+		users       int
+		orgs        int
+		members     int
+	}{
+		{
+			description: "create user",
+			call:        func(cache *Client) { cache.CreateUser(client.User{Name: "Carol"}) },
+			users:       2,
+			orgs:        1,
+			members:     2,
+		},
+		{
+			description: "delete user",
+			call:        func(cache *Client) { cache.DeleteUser("1") },
+			users:       2,
+			orgs:        1,
+			members:     4,
+		},
+		{
+			description: "create organization",
+			call:        func(cache *Client) { cache.CreateOrganization(client.Org{Name: "new"}) },
+			users:       1,
+			orgs:        2,
+			members:     2,
+		},
+		{
+			description: "delete organization",
+			call:        func(cache *Client) { cache.DeleteOrganization("1") },
+			users:       1,
+			orgs:        2,
+			members:     3,
+		},
+		{
+			description: "add member",
+			call: func(cache *Client) {
+				cache.CreateUserOrganization("2", client.UserInOrganization{Login: "bob", Role: "Viewer"})
+			},
+			users:   1,
+			orgs:    1,
+			members: 3,
+		},
+		{
+			description: "remove member",
+			call:        func(cache *Client) { cache.DeleteOrganizationUser("1", "1") },
+			users:       1,
+			orgs:        1,
+			members:     3,
+		},
+		{
+			description: "add members",
+			call: func(cache *Client) {
+				cache.AddOrganizationUsers("2", []client.UserInOrganization{{Login: "bob", Role: "Viewer"}}, 1)
+			},
+			users:   1,
+			orgs:    1,
+			members: 3,
+		},
+		{
+			description: "remove members",
+			call:        func(cache *Client) { cache.RemoveOrganizationUsers("1", []string{"1"}, 1) },
+			users:       1,
+			orgs:        1,
+			members:     3,
+		},
+		{
+			description: "copy member",
+			call:        func(cache *Client) { cache.CopyOrganizationUser("1", "1", "2", nil) },
+			users:       1,
+			orgs:        1,
+			members:     3,
+		},
+		{
+			description: "move member",
+			call:        func(cache *Client) { cache.MoveOrganizationUser("1", "1", "2", nil) },
+			users:       1,
+			orgs:        1,
+			members:     4,
+		},
+		{
+			description: "ensure user",
+			call:        func(cache *Client) { cache.EnsureUser(client.User{Login: "carol"}) },
+			users:       2,
+			orgs:        1,
+			members:     2,
+		},
+		{
+			description: "ensure organization",
+			call:        func(cache *Client) { cache.EnsureOrganization(client.Org{Name: "new"}) },
+			users:       1,
+			orgs:        2,
+			members:     2,
+		},
+		{
+			description: "ensure member",
+			call: func(cache *Client) {
+				cache.EnsureOrganizationUser("2", client.UserInOrganization{Login: "bob", Role: "Viewer"})
+			},
+			users:   1,
+			orgs:    1,
+			members: 3,
+		},
+	}
+	for _, testCase := range tests {
+		mock := newMock()
+		cache := New(mock, time.Minute)
+		read := func() {
+			cache.GetUsers()
+			cache.GetOrganizations()
+			cache.GetOrganizationUsers("1")
+			cache.GetOrganizationUsers("2")
+		}
+
+		read()
+		testCase.call(cache)
+		read()
+
+		assert.Equal(t, testCase.users, len(mock.CallsTo("GetUsers")), testCase.description)
+		assert.Equal(t, testCase.orgs, len(mock.CallsTo("GetOrganizations")), testCase.description)
+		assert.Equal(t, testCase.members, len(mock.CallsTo("GetOrganizationUsers")), testCase.description)
+	}
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	fail := true
+	mock := &clientmock.Client{
+		GetOrganizationsFunc: func() ([]client.Org, error) {
+			if fail {
+				return nil, errors.New("unavailable")
+			}
+			return []client.Org{{OrganizationID: "1", Name: "main"}}, nil
+		},
+	}
+	cache := New(mock, time.Minute)
+
+	_, err := cache.GetOrganizationName("main")
+	assert.Equal(t, errors.New("unavailable"), err)
+
+	fail = false
+	org, err := cache.GetOrganizationName("main")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1", org.OrganizationID)
+}
+
+func TestCopies(t *testing.T) {
+	cache := New(newMock(), time.Minute)
+	users, _ := cache.GetUsers()
+	users[0].Name = "changed"
+
+	user, _ := cache.GetUserID("1")
+	assert.Equal(t, "Alice", user.Name)
+	users, _ = cache.GetUsers()
+	assert.Equal(t, "Alice", users[0].Name)
+}
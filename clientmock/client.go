@@ -0,0 +1,294 @@
+// Package clientmock provides a mock of client.Client recording its calls.
+//
+// Responses are configured through the ...Func fields; a method without
+// function returns zero values and no error:
+//
+//	mock := &clientmock.Client{
+//		GetOrganizationsFunc: func() ([]client.Org, error) {
+//			return []client.Org{{OrganizationID: "1", Name: "tenant"}}, nil
+//		},
+//	}
+//	doSomething(mock)
+//	calls := mock.CallsTo("CreateUserOrganization")
+package clientmock
+
+import (
+	"sync"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// Call method called on the mock and its arguments
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Client mock of client.Client, safe for concurrent use
+type Client struct {
+	AuthenticateFunc            func() (client.AuthToken, error)
+	GetUsersFunc                func() ([]client.User, error)
+	GetUserNameFunc             func(name string) (client.User, error)
+	GetUserIDFunc               func(ID string) (client.User, error)
+	CreateUserFunc              func(user client.User) (client.User, error)
+	DeleteUserFunc              func(ID string) (client.User, error)
+	GetOrganizationsFunc        func() ([]client.Org, error)
+	GetOrganizationNameFunc     func(name string) (client.Org, error)
+	GetOrganizationIDFunc       func(OrgID string) (client.Org, error)
+	CreateOrganizationFunc      func(org client.Org) (client.Org, error)
+	DeleteOrganizationFunc      func(ID string) (client.Org, error)
+	GetOrganizationUsersFunc    func(ID string) ([]client.UserInOrganization, error)
+	GetOrganizationUserIDFunc   func(ID string, userID string) (client.UserInOrganization, error)
+	CreateUserOrganizationFunc  func(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error)
+	DeleteOrganizationUserFunc  func(userID string, orgID string) (client.UserInOrganization, error)
+	GetUserOrganizationsFunc    func(userID string) ([]client.UserOrganization, error)
+	AddOrganizationUsersFunc    func(OrgID string, users []client.UserInOrganization, concurrency int) []client.BulkResult
+	RemoveOrganizationUsersFunc func(orgID string, userIDs []string, concurrency int) []client.BulkResult
+	CopyOrganizationUserFunc    func(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (client.UserInOrganization, error)
+	MoveOrganizationUserFunc    func(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (client.UserInOrganization, error)
+	EnsureUserFunc              func(user client.User) (client.User, bool, error)
+	EnsureOrganizationFunc      func(org client.Org) (client.Org, bool, error)
+	EnsureOrganizationUserFunc  func(OrgID string, user client.UserInOrganization) (client.UserInOrganization, bool, error)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ client.Client = (*Client)(nil)
+
+func (m *Client) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns every call made so far
+func (m *Client) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made so far to method
+func (m *Client) CallsTo(method string) (calls []Call) {
+	for _, call := range m.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return
+}
+
+// Reset forgets the calls made so far
+func (m *Client) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// Authenticate records the call and runs AuthenticateFunc
+func (m *Client) Authenticate() (token client.AuthToken, err error) {
+	m.record("Authenticate")
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc()
+	}
+	return
+}
+
+// GetUsers records the call and runs GetUsersFunc
+func (m *Client) GetUsers() (users []client.User, err error) {
+	m.record("GetUsers")
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc()
+	}
+	return
+}
+
+// GetUserName records the call and runs GetUserNameFunc
+func (m *Client) GetUserName(name string) (user client.User, err error) {
+	m.record("GetUserName", name)
+	if m.GetUserNameFunc != nil {
+		return m.GetUserNameFunc(name)
+	}
+	return
+}
+
+// GetUserID records the call and runs GetUserIDFunc
+func (m *Client) GetUserID(ID string) (user client.User, err error) {
+	m.record("GetUserID", ID)
+	if m.GetUserIDFunc != nil {
+		return m.GetUserIDFunc(ID)
+	}
+	return
+}
+
+// CreateUser records the call and runs CreateUserFunc
+func (m *Client) CreateUser(user client.User) (userDetails client.User, err error) {
+	m.record("CreateUser", user)
+	if m.CreateUserFunc != nil {
+		return m.CreateUserFunc(user)
+	}
+	return
+}
+
+// DeleteUser records the call and runs DeleteUserFunc
+func (m *Client) DeleteUser(ID string) (user client.User, err error) {
+	m.record("DeleteUser", ID)
+	if m.DeleteUserFunc != nil {
+		return m.DeleteUserFunc(ID)
+	}
+	return
+}
+
+// GetOrganizations records the call and runs GetOrganizationsFunc
+func (m *Client) GetOrganizations() (orgs []client.Org, err error) {
+	m.record("GetOrganizations")
+	if m.GetOrganizationsFunc != nil {
+		return m.GetOrganizationsFunc()
+	}
+	return
+}
+
+// GetOrganizationName records the call and runs GetOrganizationNameFunc
+func (m *Client) GetOrganizationName(name string) (org client.Org, err error) {
+	m.record("GetOrganizationName", name)
+	if m.GetOrganizationNameFunc != nil {
+		return m.GetOrganizationNameFunc(name)
+	}
+	return
+}
+
+// GetOrganizationID records the call and runs GetOrganizationIDFunc
+func (m *Client) GetOrganizationID(OrgID string) (org client.Org, err error) {
+	m.record("GetOrganizationID", OrgID)
+	if m.GetOrganizationIDFunc != nil {
+		return m.GetOrganizationIDFunc(OrgID)
+	}
+	return
+}
+
+// CreateOrganization records the call and runs CreateOrganizationFunc
+func (m *Client) CreateOrganization(org client.Org) (orgs client.Org, err error) {
+	m.record("CreateOrganization", org)
+	if m.CreateOrganizationFunc != nil {
+		return m.CreateOrganizationFunc(org)
+	}
+	return
+}
+
+// DeleteOrganization records the call and runs DeleteOrganizationFunc
+func (m *Client) DeleteOrganization(ID string) (org client.Org, err error) {
+	m.record("DeleteOrganization", ID)
+	if m.DeleteOrganizationFunc != nil {
+		return m.DeleteOrganizationFunc(ID)
+	}
+	return
+}
+
+// GetOrganizationUsers records the call and runs GetOrganizationUsersFunc
+func (m *Client) GetOrganizationUsers(ID string) (users []client.UserInOrganization, err error) {
+	m.record("GetOrganizationUsers", ID)
+	if m.GetOrganizationUsersFunc != nil {
+		return m.GetOrganizationUsersFunc(ID)
+	}
+	return
+}
+
+// GetOrganizationUserID records the call and runs GetOrganizationUserIDFunc
+func (m *Client) GetOrganizationUserID(ID string, userID string) (user client.UserInOrganization, err error) {
+	m.record("GetOrganizationUserID", ID, userID)
+	if m.GetOrganizationUserIDFunc != nil {
+		return m.GetOrganizationUserIDFunc(ID, userID)
+	}
+	return
+}
+
+// CreateUserOrganization records the call and runs CreateUserOrganizationFunc
+func (m *Client) CreateUserOrganization(OrgID string, user client.UserInOrganization) (org client.UserInOrganization, err error) {
+	m.record("CreateUserOrganization", OrgID, user)
+	if m.CreateUserOrganizationFunc != nil {
+		return m.CreateUserOrganizationFunc(OrgID, user)
+	}
+	return
+}
+
+// DeleteOrganizationUser records the call and runs DeleteOrganizationUserFunc
+func (m *Client) DeleteOrganizationUser(userID string, orgID string) (org client.UserInOrganization, err error) {
+	m.record("DeleteOrganizationUser", userID, orgID)
+	if m.DeleteOrganizationUserFunc != nil {
+		return m.DeleteOrganizationUserFunc(userID, orgID)
+	}
+	return
+}
+
+// GetUserOrganizations records the call and runs GetUserOrganizationsFunc
+func (m *Client) GetUserOrganizations(userID string) (orgs []client.UserOrganization, err error) {
+	m.record("GetUserOrganizations", userID)
+	if m.GetUserOrganizationsFunc != nil {
+		return m.GetUserOrganizationsFunc(userID)
+	}
+	return
+}
+
+// AddOrganizationUsers records the call and runs AddOrganizationUsersFunc
+func (m *Client) AddOrganizationUsers(OrgID string, users []client.UserInOrganization, concurrency int) []client.BulkResult {
+	m.record("AddOrganizationUsers", OrgID, users, concurrency)
+	if m.AddOrganizationUsersFunc != nil {
+		return m.AddOrganizationUsersFunc(OrgID, users, concurrency)
+	}
+	return nil
+}
+
+// RemoveOrganizationUsers records the call and runs RemoveOrganizationUsersFunc
+func (m *Client) RemoveOrganizationUsers(orgID string, userIDs []string, concurrency int) []client.BulkResult {
+	m.record("RemoveOrganizationUsers", orgID, userIDs, concurrency)
+	if m.RemoveOrganizationUsersFunc != nil {
+		return m.RemoveOrganizationUsersFunc(orgID, userIDs, concurrency)
+	}
+	return nil
+}
+
+// CopyOrganizationUser records the call and runs CopyOrganizationUserFunc
+func (m *Client) CopyOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (user client.UserInOrganization, err error) {
+	m.record("CopyOrganizationUser", userID, fromOrgID, toOrgID, mapRole)
+	if m.CopyOrganizationUserFunc != nil {
+		return m.CopyOrganizationUserFunc(userID, fromOrgID, toOrgID, mapRole)
+	}
+	return
+}
+
+// MoveOrganizationUser records the call and runs MoveOrganizationUserFunc
+func (m *Client) MoveOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (user client.UserInOrganization, err error) {
+	m.record("MoveOrganizationUser", userID, fromOrgID, toOrgID, mapRole)
+	if m.MoveOrganizationUserFunc != nil {
+		return m.MoveOrganizationUserFunc(userID, fromOrgID, toOrgID, mapRole)
+	}
+	return
+}
+
+// EnsureUser records the call and runs EnsureUserFunc
+func (m *Client) EnsureUser(user client.User) (existing client.User, changed bool, err error) {
+	m.record("EnsureUser", user)
+	if m.EnsureUserFunc != nil {
+		return m.EnsureUserFunc(user)
+	}
+	return
+}
+
+// EnsureOrganization records the call and runs EnsureOrganizationFunc
+func (m *Client) EnsureOrganization(org client.Org) (existing client.Org, changed bool, err error) {
+	m.record("EnsureOrganization", org)
+	if m.EnsureOrganizationFunc != nil {
+		return m.EnsureOrganizationFunc(org)
+	}
+	return
+}
+
+// EnsureOrganizationUser records the call and runs EnsureOrganizationUserFunc
+func (m *Client) EnsureOrganizationUser(OrgID string, user client.UserInOrganization) (member client.UserInOrganization, changed bool, err error) {
+	m.record("EnsureOrganizationUser", OrgID, user)
+	if m.EnsureOrganizationUserFunc != nil {
+		return m.EnsureOrganizationUserFunc(OrgID, user)
+	}
+	return
+}
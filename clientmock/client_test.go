@@ -0,0 +1,102 @@
+package clientmock
+
+import (
+	"errors"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfiguredResponses(t *testing.T) {
+	mock := &Client{
+		GetOrganizationsFunc: func() ([]client.Org, error) {
+			return []client.Org{{OrganizationID: "1", Name: "tenant"}}, nil
+		},
+		DeleteUserFunc: func(ID string) (client.User, error) {
+			return client.User{}, errors.New("boom")
+		},
+	}
+
+	orgs, err := mock.GetOrganizations()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, []client.Org{{OrganizationID: "1", Name: "tenant"}}, orgs)
+
+	_, err = mock.DeleteUser("1")
+	assert.Equal(t, errors.New("boom"), err)
+
+	users, err := mock.GetUsers()
+	assert.Equal(t, err, nil, "unconfigured method succeeds")
+	assert.Nil(t, users)
+}
+
+func TestCallRecording(t *testing.T) {
+	var api client.Client = &Client{}
+	mock := api.(*Client)
+
+	api.CreateUserOrganization("1", client.UserInOrganization{Login: "alice", Role: "Viewer"})
+	api.DeleteOrganizationUser("2", "1")
+	api.CreateUserOrganization("3", client.UserInOrganization{Login: "bob", Role: "Admin"})
+
+	assert.Equal(t, 3, len(mock.Calls()))
+	assert.Equal(t, []Call{
+		{Method: "CreateUserOrganization", Args: []interface{}{"1", client.UserInOrganization{Login: "alice", Role: "Viewer"}}},
+		{Method: "CreateUserOrganization", Args: []interface{}{"3", client.UserInOrganization{Login: "bob", Role: "Admin"}}},
+	}, mock.CallsTo("CreateUserOrganization"))
+	assert.Equal(t, []interface{}{"2", "1"}, mock.CallsTo("DeleteOrganizationUser")[0].Args)
+
+	mock.Reset()
+	assert.Equal(t, 0, len(mock.Calls()))
+}
+
+func TestBulkResults(t *testing.T) {
+	mock := &Client{
+		AddOrganizationUsersFunc: func(OrgID string, users []client.UserInOrganization, concurrency int) []client.BulkResult {
+			return []client.BulkResult{{User: users[0], Status: client.BulkFailed, Err: errors.New("boom")}}
+		},
+	}
+	var api client.Client = mock
+
+	users := []client.UserInOrganization{{Login: "alice", Role: "Viewer"}}
+	results := api.AddOrganizationUsers("1", users, 4)
+	assert.Equal(t, []client.BulkResult{{User: users[0], Status: client.BulkFailed, Err: errors.New("boom")}}, results)
+	assert.Nil(t, api.RemoveOrganizationUsers("1", []string{"2"}, 4), "unconfigured method")
+	assert.Equal(t, []interface{}{"1", []string{"2"}, 4}, mock.CallsTo("RemoveOrganizationUsers")[0].Args)
+}
+
+func TestMoveOrganizationUser(t *testing.T) {
+	mock := &Client{
+		MoveOrganizationUserFunc: func(userID string, fromOrgID string, toOrgID string, mapRole client.RoleMapping) (client.UserInOrganization, error) {
+			return client.UserInOrganization{OrgID: toOrgID, UserID: userID, Role: mapRole(client.RoleAdmin)}, nil
+		},
+	}
+	var api client.Client = mock
+
+	user, err := api.MoveOrganizationUser("3", "1", "2", client.KeepRole)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, client.UserInOrganization{OrgID: "2", UserID: "3", Role: client.RoleAdmin}, user)
+	assert.Equal(t, "2", mock.CallsTo("MoveOrganizationUser")[0].Args[2])
+
+	_, err = api.CopyOrganizationUser("3", "1", "2", nil)
+	assert.Equal(t, err, nil, "unconfigured method succeeds")
+}
+
+func TestEnsureOrganizationUser(t *testing.T) {
+	mock := &Client{
+		EnsureOrganizationUserFunc: func(OrgID string, user client.UserInOrganization) (client.UserInOrganization, bool, error) {
+			user.OrgID = OrgID
+			return user, true, nil
+		},
+	}
+	var api client.Client = mock
+
+	member, changed, err := api.EnsureOrganizationUser("1", client.UserInOrganization{Login: "alice", Role: client.RoleViewer})
+	assert.Equal(t, err, nil, "no error")
+	assert.True(t, changed)
+	assert.Equal(t, "1", member.OrgID)
+
+	_, changed, err = api.EnsureOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, err, nil, "unconfigured method succeeds")
+	assert.False(t, changed)
+	assert.Equal(t, 1, len(mock.CallsTo("EnsureOrganization")))
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// completionTTL how long completion candidates fetched from the API are reused
+const completionTTL = 30 * time.Second
+
+// Kinds of values completed dynamically
+const (
+	completeOrgID     = "org-id"
+	completeOrgName   = "org-name"
+	completeUserID    = "user-id"
+	completeUserName  = "user-name"
+	completeUserLogin = "user-login"
+	completeRole      = "role"
+	completeOutput    = "output"
+)
+
+// globalFlagCompletion completion of the values of the global flags
+var globalFlagCompletion = map[string]string{"o": completeOutput}
+
+const bashCompletion = `# bash completion for vizctl, load with: source <(vizctl completion bash)
+_vizctl() {
+    local IFS=$'\n'
+    COMPREPLY=($(vizctl __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -F _vizctl vizctl
+`
+
+const zshCompletion = `#compdef vizctl
+# zsh completion for vizctl, load with: source <(vizctl completion zsh)
+_vizctl() {
+    local -a candidates
+    local line value
+    for line in "${(@f)$(vizctl __complete "${(@)words[2,$CURRENT]}" 2>/dev/null)}"; do
+        [[ -z "$line" ]] && continue
+        value=${line%%$'\t'*}
+        candidates+=("${value//:/\\:}:${line#*$'\t'}")
+    done
+    _describe 'vizctl' candidates
+}
+compdef _vizctl vizctl
+`
+
+const fishCompletion = `# fish completion for vizctl, load with: vizctl completion fish | source
+function __vizctl_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    vizctl __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c vizctl -f -a '(__vizctl_complete)'
+`
+
+func completionCommand() *command {
+	script := func(name string, body string) *command {
+		return &command{
+			name:        name,
+			description: "Print the " + name + " completion script",
+			run: func(c *cli, args []string) error {
+				if len(args) != 0 {
+					return errUsage
+				}
+				_, err := fmt.Fprint(c.stdout, body)
+				return err
+			},
+		}
+	}
+
+	return &command{
+		name:        "completion",
+		description: "Print shell completion scripts",
+		subcommands: []*command{
+			script("bash", bashCompletion),
+			script("zsh", zshCompletion),
+			script("fish", fishCompletion),
+		},
+	}
+}
+
+func completeCommand() *command {
+	return &command{
+		name:        "__complete",
+		description: "Print the completion candidates of a partial command line",
+		hidden:      true,
+		rawArgs:     true,
+		run: func(c *cli, args []string) error {
+			for _, candidate := range c.complete(args) {
+				fmt.Fprintln(c.stdout, candidate)
+			}
+			return nil
+		},
+	}
+}
+
+// complete returns the candidates, as "value" or "value\tdescription" lines,
+// for the last word of words
+func (c *cli) complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	cmd := rootCommand()
+	positional := 0
+
+	for i := 0; i < len(words)-1; i++ {
+		word := words[i]
+		if strings.HasPrefix(word, "-") {
+			name := strings.TrimLeft(word, "-")
+			if strings.Contains(name, "=") {
+				parts := strings.SplitN(name, "=", 2)
+				c.setGlobalFlag(parts[0], parts[1])
+				continue
+			}
+			if c.boolFlag(cmd, name) {
+				// takes no value, the next word is completed on its own
+				continue
+			}
+			if i+1 < len(words)-1 {
+				c.setGlobalFlag(name, words[i+1])
+			}
+			if i+1 == len(words)-1 {
+				return c.completeValue(flagCompletion(cmd, name), current)
+			}
+			i++
+			continue
+		}
+
+		if cmd.run != nil {
+			positional++
+			continue
+		}
+		cmd = cmd.find(word)
+		if cmd == nil {
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		return c.completeFlags(cmd, current)
+	}
+	if cmd.run == nil {
+		var candidates []string
+		for _, sub := range cmd.subcommands {
+			if !sub.hidden && strings.HasPrefix(sub.name, current) {
+				candidates = append(candidates, sub.name+"\t"+sub.description)
+			}
+		}
+		return candidates
+	}
+	if positional < len(cmd.complete) {
+		return c.completeValue(cmd.complete[positional], current)
+	}
+	return nil
+}
+
+// setGlobalFlag records the global flags met on the command line so the
+// completion queries the same API as the command would
+func (c *cli) setGlobalFlag(name string, value string) {
+	switch name {
+	case "url":
+		c.flags.URL = value
+	case "token":
+		c.flags.Token = value
+	case "config":
+		c.flags.path = value
+	}
+}
+
+func flagCompletion(cmd *command, name string) string {
+	if kind, ok := cmd.flagCompletion[name]; ok {
+		return kind
+	}
+	return globalFlagCompletion[name]
+}
+
+// commandFlags returns the global flags and those of cmd
+func (c *cli) commandFlags(cmd *command) *flag.FlagSet {
+	fs := c.flagSet("")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	return fs
+}
+
+// boolFlag reports whether the flag name of cmd is a boolean
+func (c *cli) boolFlag(cmd *command, name string) bool {
+	f := c.commandFlags(cmd).Lookup(name)
+	if f == nil {
+		return false
+	}
+	value, ok := f.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && value.IsBoolFlag()
+}
+
+func (c *cli) completeFlags(cmd *command, current string) (candidates []string) {
+	fs := c.commandFlags(cmd)
+	fs.VisitAll(func(f *flag.Flag) {
+		name := "--" + f.Name
+		if len(f.Name) == 1 {
+			name = "-" + f.Name
+		}
+		if strings.HasPrefix(name, current) {
+			candidates = append(candidates, name+"\t"+f.Usage)
+		}
+	})
+	return
+}
+
+// completeValue returns the candidates of a kind of value
+func (c *cli) completeValue(kind string, current string) (candidates []string) {
+	add := func(value string, description string) {
+		if strings.HasPrefix(value, current) {
+			candidates = append(candidates, value+"\t"+description)
+		}
+	}
+
+	switch kind {
+	case completeRole:
+		for _, role := range []string{client.RoleViewer, client.RoleEditor, client.RoleAdmin} {
+			add(role, "role")
+		}
+	case completeOutput:
+		for _, format := range []string{"table", "json", "yaml"} {
+			add(format, "output format")
+		}
+	case completeOrgID, completeOrgName:
+		var orgs []client.Org
+		err := c.cached("orgs", &orgs, func(api client.Client) (interface{}, error) {
+			return api.GetOrganizations()
+		})
+		if err != nil {
+			return nil
+		}
+		for _, org := range orgs {
+			if kind == completeOrgID {
+				add(org.OrganizationID, org.Name)
+			} else {
+				add(org.Name, "organization "+org.OrganizationID)
+			}
+		}
+	case completeUserID, completeUserName, completeUserLogin:
+		var users []client.User
+		err := c.cached("users", &users, func(api client.Client) (interface{}, error) {
+			return api.GetUsers()
+		})
+		if err != nil {
+			return nil
+		}
+		for _, user := range users {
+			switch kind {
+			case completeUserID:
+				add(user.UserID, user.Login)
+			case completeUserName:
+				add(user.Name, "user "+user.UserID)
+			default:
+				add(user.Login, user.Name)
+			}
+		}
+	}
+	return
+}
+
+// cacheDir returns the directory of the completion cache
+func (c *cli) cacheDir() string {
+	if dir := c.getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "vizctl")
+	}
+	if home := c.getenv("HOME"); home != "" {
+		return filepath.Join(home, ".cache", "vizctl")
+	}
+	return filepath.Join(os.TempDir(), "vizctl")
+}
+
+type cacheEntry struct {
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// cached decodes into value the result of fetch, reusing the result of a
+// previous call against the same API and token for completionTTL
+func (c *cli) cached(name string, value interface{}, fetch func(api client.Client) (interface{}, error)) error {
+	err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+	key := sha1.Sum([]byte(c.config.URL + "\x00" + c.config.Token))
+	path := filepath.Join(c.cacheDir(), hex.EncodeToString(key[:8])+"-"+name+".json")
+
+	var entry cacheEntry
+	data, err := ioutil.ReadFile(path)
+	if err == nil && json.Unmarshal(data, &entry) == nil && time.Since(entry.Time) < completionTTL {
+		return json.Unmarshal(entry.Data, value)
+	}
+
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	result, err := fetch(api)
+	if err != nil {
+		return err
+	}
+	entry.Time = time.Now()
+	entry.Data, err = json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	// the cache is best effort, completion works without it
+	if os.MkdirAll(filepath.Dir(path), 0700) == nil {
+		if data, err = json.Marshal(entry); err == nil {
+			ioutil.WriteFile(path, data, 0600)
+		}
+	}
+	return json.Unmarshal(entry.Data, value)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vizctl")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+
+	var calls []string
+	ts := newServer(`[{"organizationID":"2","name":"tenant-a"},{"organizationID":"3","name":"tenant-b"}]`, &calls)
+	defer ts.Close()
+	env := map[string]string{"VIZCTL_URL": ts.URL, "XDG_CACHE_HOME": dir}
+
+	tests := []struct {
+		description string
+		words       []string
+		expected    []string
+	}{
+		{
+			description: "commands",
+			words:       []string{""},
+			expected: []string{
+				"auth\tAuthenticate with the OpenStack token and print the issued token",
+				"users\tManage users",
+				"orgs\tManage organizations and their members",
+				"snapshot\tExport or restore organizations, users and memberships",
+				"completion\tPrint shell completion scripts",
+			},
+		},
+		{
+			description: "subcommands with prefix",
+			words:       []string{"orgs", "m"},
+			expected:    []string{"members\tManage the members of an organization"},
+		},
+		{
+			description: "organization ids",
+			words:       []string{"orgs", "members", "remove", ""},
+			expected:    []string{"2\ttenant-a", "3\ttenant-b"},
+		},
+		{
+			description: "organization names, from the cache",
+			words:       []string{"orgs", "get", "--name", "tenant-b"},
+			expected:    []string{"tenant-b\torganization 3"},
+		},
+		{
+			description: "flags",
+			words:       []string{"orgs", "members", "add", "2", "--r"},
+			expected:    []string{"--role\trole: Viewer, Editor or Admin"},
+		},
+		{
+			description: "flag values",
+			words:       []string{"-o", ""},
+			expected:    []string{"table\toutput format", "json\toutput format", "yaml\toutput format"},
+		},
+		{
+			description: "after a boolean flag",
+			words:       []string{"orgs", "delete", "--preview", ""},
+			expected:    []string{"2\ttenant-a", "3\ttenant-b"},
+		},
+		{
+			description: "flags after a boolean flag",
+			words:       []string{"snapshot", "restore", "--dry-run", "--d"},
+			expected:    []string{"--dry-run\treport what would be created without changing anything"},
+		},
+		{
+			description: "no more positional arguments",
+			words:       []string{"orgs", "delete", "2", ""},
+			expected:    nil,
+		},
+	}
+	for _, testCase := range tests {
+		code, stdout, _ := runCLI(env, append([]string{"__complete"}, testCase.words...)...)
+		assert.Equal(t, 0, code, testCase.description)
+		var lines []string
+		if stdout != "" {
+			lines = strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+		}
+		assert.Equal(t, testCase.expected, lines, testCase.description)
+	}
+	assert.Equal(t, []string{"GET /admin/organizations"}, calls, "organizations fetched once")
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		code, stdout, _ := runCLI(nil, "completion", shell)
+		assert.Equal(t, 0, code, shell)
+		assert.Contains(t, stdout, "vizctl __complete", shell)
+	}
+
+	_, _, stderr := runCLI(nil)
+	assert.NotContains(t, stderr, "__complete", "hidden command")
+}
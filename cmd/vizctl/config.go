@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// config settings of vizctl
+type config struct {
+	URL    string `yaml:"url"`
+	Token  string `yaml:"token"`
+	Output string `yaml:"output"`
+
+	path string
+}
+
+// loadConfig merges the configuration file, the environment and the flags,
+// the latter taking precedence
+func (c *cli) loadConfig() error {
+	path := c.flags.path
+	if path == "" {
+		path = c.getenv("VIZCTL_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit && c.getenv("HOME") != "" {
+		path = filepath.Join(c.getenv("HOME"), ".vizctl.yaml")
+	}
+
+	c.config = config{}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err) && !explicit:
+		case err != nil:
+			return err
+		default:
+			err = yaml.UnmarshalStrict(data, &c.config)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+
+	override(&c.config.URL, c.getenv("VIZCTL_URL"), c.flags.URL)
+	override(&c.config.Token, c.getenv("VIZCTL_TOKEN"), c.flags.Token)
+	override(&c.config.Output, c.getenv("VIZCTL_OUTPUT"), c.flags.Output)
+	return nil
+}
+
+// override sets value to the last non empty of values
+func override(value *string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			*value = v
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Command vizctl manages users, organizations and memberships of the
+// visualization API from the command line.
+//
+// Usage:
+//
+//	vizctl [flags] <command> [subcommand] [flags] [arguments]
+//
+// The API URL and OpenStack token are read from the --url and --token
+// flags, then the VIZCTL_URL and VIZCTL_TOKEN environment variables, then the
+// YAML configuration file given by --config, VIZCTL_CONFIG or ~/.vizctl.yaml.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// errUsage is returned by commands called with wrong arguments
+var errUsage = errors.New("invalid usage")
+
+// command node of the command tree, either a group or a leaf with run
+type command struct {
+	name        string
+	args        string
+	description string
+	flags       func(fs *flag.FlagSet)
+	run         func(c *cli, args []string) error
+	subcommands []*command
+
+	// hidden commands are not listed in the usage
+	hidden bool
+	// rawArgs commands get their arguments without flag parsing
+	rawArgs bool
+	// complete kinds of the positional arguments, for shell completion
+	complete []string
+	// flagCompletion kinds of the flag values, for shell completion
+	flagCompletion map[string]string
+}
+
+// cli state shared by the commands of one invocation
+type cli struct {
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	flags  config
+	config config
+	fs     *flag.FlagSet
+	api    client.Client
+}
+
+func main() {
+	c := &cli{stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run executes the command line and returns the exit code
+func (c *cli) run(args []string) int {
+	root := rootCommand()
+
+	fs := c.flagSet("vizctl")
+	fs.Usage = func() { c.usage(root, "vizctl") }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, path, rest := root, "vizctl", fs.Args()
+	for cmd.run == nil {
+		if len(rest) == 0 {
+			c.usage(cmd, path)
+			return 2
+		}
+		next := cmd.find(rest[0])
+		if next == nil {
+			fmt.Fprintf(c.stderr, "unknown command %q\n", strings.TrimSpace(path+" "+rest[0]))
+			c.usage(cmd, path)
+			return 2
+		}
+		cmd, path, rest = next, path+" "+next.name, rest[1:]
+	}
+
+	fs = c.flagSet(path)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() { c.usage(cmd, path) }
+	positional := rest
+	if !cmd.rawArgs {
+		var err error
+		positional, err = parseInterspersed(fs, rest)
+		if err != nil {
+			return 2
+		}
+	}
+
+	c.fs = fs
+	err := cmd.run(c, positional)
+	if err == errUsage {
+		c.usage(cmd, path)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses flags placed before, between or after the
+// positional arguments and returns the latter
+func parseInterspersed(fs *flag.FlagSet, args []string) (positional []string, err error) {
+	for {
+		err = fs.Parse(args)
+		if err != nil {
+			return
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func (cmd *command) find(name string) *command {
+	for _, sub := range cmd.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// flagSet returns a flag set knowing the global flags, so they can be given
+// before or after the command name
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.flags.URL, "url", c.flags.URL, "visualization API URL")
+	fs.StringVar(&c.flags.Token, "token", c.flags.Token, "OpenStack token")
+	fs.StringVar(&c.flags.Output, "o", c.flags.Output, "output format: table, json or yaml")
+	fs.StringVar(&c.flags.path, "config", c.flags.path, "configuration file")
+	return fs
+}
+
+func (c *cli) usage(cmd *command, path string) {
+	if cmd.run != nil {
+		fmt.Fprintf(c.stderr, "Usage: %s [flags] %s\n\n%s\n\nFlags:\n", path, cmd.args, cmd.description)
+		fs := c.flagSet(path)
+		if cmd.flags != nil {
+			cmd.flags(fs)
+		}
+		fs.PrintDefaults()
+		return
+	}
+
+	fmt.Fprintf(c.stderr, "Usage: %s <command>\n\nCommands:\n", path)
+	for _, sub := range cmd.subcommands {
+		if !sub.hidden {
+			fmt.Fprintf(c.stderr, "  %-10s %s\n", sub.name, sub.description)
+		}
+	}
+}
+
+// flag returns the value of a flag of the running command
+func (c *cli) flag(name string) string {
+	f := c.fs.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// client returns the visualization client built from the configuration
+func (c *cli) client() (client.Client, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+
+	err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if c.config.URL == "" {
+		return nil, errors.New("no API URL configured, use --url or VIZCTL_URL")
+	}
+
+	c.api, err = client.NewVisualizationClient(strings.TrimRight(c.config.URL, "/"), http.Client{}, c.config.Token)
+	return c.api, err
+}
+
+// readPassword reads a password flag value, "-" meaning stdin
+func readPassword(value string) (string, error) {
+	if value != "-" {
+		return value, nil
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	return strings.TrimRight(string(data), "\r\n"), err
+}
+
+func rootCommand() *command {
+	return &command{subcommands: []*command{
+		authCommand(),
+		usersCommand(),
+		orgsCommand(),
+		snapshotCommand(),
+		completionCommand(),
+		completeCommand(),
+	}}
+}
+
+func authCommand() *command {
+	return &command{
+		name:        "auth",
+		description: "Authenticate with the OpenStack token and print the issued token",
+		run: func(c *cli, args []string) error {
+			if len(args) != 0 {
+				return errUsage
+			}
+			api, err := c.client()
+			if err != nil {
+				return err
+			}
+			token, err := api.Authenticate()
+			if err != nil {
+				return err
+			}
+			return c.print(token, []string{"ORGANIZATION", "ADMIN", "EXPIRES"}, [][]string{{
+				token.Token.OrganizationID, fmt.Sprint(token.Token.IsAdmin), token.Token.ExpiresAt.String()}})
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newServer answers every API call with body and records "METHOD path" of
+// the calls that are not authentications
+func newServer(body string, calls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt","token":{"organizationId":"1","isAdmin":true}}`)
+			return
+		}
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, body)
+	}))
+}
+
+func runCLI(env map[string]string, args ...string) (code int, stdout string, stderr string) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	c := &cli{stdout: out, stderr: errOut, getenv: func(key string) string { return env[key] }}
+	code = c.run(args)
+	return code, out.String(), errOut.String()
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		description string
+		args        []string
+		body        string
+		expectedOut string
+		expectedAPI []string
+	}{
+		{
+			description: "users list as table",
+			args:        []string{"users", "list"},
+			body:        `[{"userID":"1","login":"alice","name":"Alice","email":"alice@example.com"}]`,
+			expectedOut: "ID  LOGIN  NAME   EMAIL\n1   alice  Alice  alice@example.com\n",
+			expectedAPI: []string{"GET /admin/users"},
+		},
+		{
+			description: "users get as json",
+			args:        []string{"-o", "json", "users", "get", "1"},
+			body:        `{"userID":"1","login":"alice"}`,
+			expectedOut: "{\n  \"userID\": \"1\",\n  \"email\": \"\",\n  \"name\": \"\",\n  \"login\": \"alice\",\n  \"password\": \"\",\n  \"orgID\": \"\"\n}\n",
+			expectedAPI: []string{"GET /admin/users/1"},
+		},
+		{
+			description: "users orgs",
+			args:        []string{"users", "orgs", "1"},
+			body:        `[{"orgID":"2","name":"tenant","role":"Viewer"}]`,
+			expectedOut: "ID  NAME    ROLE\n2   tenant  Viewer\n",
+			expectedAPI: []string{"GET /admin/users/1/organizations"},
+		},
+		{
+			description: "orgs list as yaml, output flag after the command",
+			args:        []string{"orgs", "list", "-o", "yaml"},
+			body:        `[{"organizationID":"2","name":"tenant"}]`,
+			expectedOut: "- organizationID: \"2\"\n  name: tenant\n",
+			expectedAPI: []string{"GET /admin/organizations"},
+		},
+		{
+			description: "orgs delete",
+			args:        []string{"orgs", "delete", "2"},
+			body:        `{"organizationID":"2","name":"tenant"}`,
+			expectedOut: "ID  NAME\n2   tenant\n",
+			expectedAPI: []string{"DELETE /admin/organizations/2"},
+		},
+		{
+			description: "members add",
+			args:        []string{"orgs", "members", "add", "2", "--login", "alice", "--role", "Editor"},
+			body:        `{"orgID":"2","userID":"1","login":"alice","role":"Editor"}`,
+			expectedOut: "ORG  USER  LOGIN  EMAIL  ROLE\n2    1     alice         Editor\n",
+			expectedAPI: []string{"POST /admin/organizations/2/users"},
+		},
+		{
+			description: "members remove",
+			args:        []string{"orgs", "members", "remove", "2", "1"},
+			body:        `{"orgID":"2","userID":"1","login":"alice","role":"Editor"}`,
+			expectedOut: "ORG  USER  LOGIN  EMAIL  ROLE\n2    1     alice         Editor\n",
+			expectedAPI: []string{"DELETE /admin/organizations/2/users/1"},
+		},
+	}
+	for _, testCase := range tests {
+		var calls []string
+		ts := newServer(testCase.body, &calls)
+		defer ts.Close()
+
+		code, stdout, stderr := runCLI(map[string]string{"VIZCTL_URL": ts.URL}, testCase.args...)
+		assert.Equal(t, 0, code, testCase.description+": "+stderr)
+		assert.Equal(t, testCase.expectedOut, stdout, testCase.description)
+		assert.Equal(t, testCase.expectedAPI, calls, testCase.description)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	code, _, stderr := runCLI(nil, "orgs", "members")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: vizctl orgs members <command>")
+
+	code, _, stderr = runCLI(nil, "users", "get")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: vizctl users get [flags] <user-id> | --name <name>")
+
+	code, _, stderr = runCLI(nil, "users", "list")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no API URL configured")
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vizctl")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	err = ioutil.WriteFile(path, []byte("url: http://file\ntoken: file-token\noutput: json\n"), 0600)
+	assert.Equal(t, err, nil, "no error")
+
+	c := &cli{getenv: func(key string) string {
+		return map[string]string{"VIZCTL_CONFIG": path, "VIZCTL_TOKEN": "env-token"}[key]
+	}}
+	c.flags.Output = "yaml"
+	err = c.loadConfig()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, "http://file", c.config.URL)
+	assert.Equal(t, "env-token", c.config.Token)
+	assert.Equal(t, "yaml", c.config.Output)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/safedelete"
+	"github.com/kbhonagiri16/visualization-client/snapshot"
+)
+
+func orgsCommand() *command {
+	return &command{
+		name:        "orgs",
+		description: "Manage organizations and their members",
+		subcommands: []*command{
+			{
+				name:        "list",
+				description: "List organizations",
+				run:         runOrgsList,
+			},
+			{
+				name:        "get",
+				args:        "<org-id> | --name <name>",
+				description: "Show an organization by ID or by name",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("name", "", "organization name")
+				},
+				run:            runOrgsGet,
+				complete:       []string{completeOrgID},
+				flagCompletion: map[string]string{"name": completeOrgName},
+			},
+			{
+				name:        "create",
+				args:        "<name>",
+				description: "Create an organization",
+				run:         runOrgsCreate,
+			},
+			{
+				name:        "delete",
+				args:        "<org-id> [--preview | --confirm <token> [--export <file>]]",
+				description: "Delete an organization, or preview what the deletion destroys",
+				flags: func(fs *flag.FlagSet) {
+					fs.Bool("preview", false, "show the members and dashboards destroyed and the confirmation token")
+					fs.String("confirm", "", "delete only when the confirmation token of the preview still matches")
+					fs.String("export", "", "snapshot file written before deleting, with --confirm")
+				},
+				run:      runOrgsDelete,
+				complete: []string{completeOrgID},
+			},
+			membersCommand(),
+		},
+	}
+}
+
+func membersCommand() *command {
+	return &command{
+		name:        "members",
+		description: "Manage the members of an organization",
+		subcommands: []*command{
+			{
+				name:        "list",
+				args:        "<org-id>",
+				description: "List the members of an organization",
+				run:         runMembersList,
+				complete:    []string{completeOrgID},
+			},
+			{
+				name:        "get",
+				args:        "<org-id> <user-id>",
+				description: "Show a member of an organization",
+				run:         runMembersGet,
+				complete:    []string{completeOrgID, completeUserID},
+			},
+			{
+				name:        "add",
+				args:        "<org-id> --login <login> --role <role> [--email <email>]",
+				description: "Add a user to an organization",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("login", "", "user login")
+					fs.String("email", "", "user email")
+					fs.String("role", client.RoleViewer, "role: Viewer, Editor or Admin")
+				},
+				run:            runMembersAdd,
+				complete:       []string{completeOrgID},
+				flagCompletion: map[string]string{"login": completeUserLogin, "role": completeRole},
+			},
+			{
+				name:        "remove",
+				args:        "<org-id> <user-id>",
+				description: "Remove a user from an organization",
+				run:         runMembersRemove,
+				complete:    []string{completeOrgID, completeUserID},
+			},
+		},
+	}
+}
+
+func (c *cli) printOrgs(value interface{}, orgs ...client.Org) error {
+	rows := make([][]string, 0, len(orgs))
+	for _, org := range orgs {
+		rows = append(rows, []string{org.OrganizationID, org.Name})
+	}
+	return c.print(value, []string{"ID", "NAME"}, rows)
+}
+
+func (c *cli) printMembers(value interface{}, users ...client.UserInOrganization) error {
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, []string{user.OrgID, user.UserID, user.Login, user.Email, user.Role})
+	}
+	return c.print(value, []string{"ORG", "USER", "LOGIN", "EMAIL", "ROLE"}, rows)
+}
+
+func runOrgsList(c *cli, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	orgs, err := api.GetOrganizations()
+	if err != nil {
+		return err
+	}
+	if orgs == nil {
+		orgs = []client.Org{}
+	}
+	return c.printOrgs(orgs, orgs...)
+}
+
+func runOrgsGet(c *cli, args []string) error {
+	name := c.flag("name")
+	if (name == "") == (len(args) != 1) || len(args) > 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var org client.Org
+	if name != "" {
+		org, err = api.GetOrganizationName(name)
+	} else {
+		org, err = api.GetOrganizationID(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return c.printOrgs(org, org)
+}
+
+func runOrgsCreate(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	org, err := api.CreateOrganization(client.Org{Name: args[0]})
+	if err != nil {
+		return err
+	}
+	return c.printOrgs(org, org)
+}
+
+func runOrgsDelete(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	if c.flag("preview") == "true" || c.flag("confirm") != "" {
+		return runOrgsSafeDelete(c, api, args[0])
+	}
+	if c.flag("export") != "" {
+		return errUsage
+	}
+
+	org, err := api.DeleteOrganization(args[0])
+	if err != nil {
+		return err
+	}
+	return c.printOrgs(org, org)
+}
+
+// runOrgsSafeDelete previews the deletion of the organization with ID orgID,
+// or deletes it when confirmed
+func runOrgsSafeDelete(c *cli, api client.Client, orgID string) error {
+	if c.flag("preview") == "true" {
+		if c.flag("confirm") != "" || c.flag("export") != "" {
+			return errUsage
+		}
+		impact, err := safedelete.Preview(api, orgID, safedelete.Options{})
+		if err != nil {
+			return err
+		}
+		if c.config.Output == "table" || c.config.Output == "" {
+			return impact.Write(c.stdout)
+		}
+		return c.print(impact, nil, nil)
+	}
+
+	opts := safedelete.Options{}
+	file := c.flag("export")
+	var f *os.File
+	if file != "" {
+		// never touch the export before the deletion is confirmed
+		impact, err := safedelete.Preview(api, orgID, opts)
+		if err != nil {
+			return err
+		}
+		if c.flag("confirm") != impact.ConfirmationToken() {
+			return safedelete.ErrConfirmation
+		}
+		f, err = createTemp(file)
+		if err != nil {
+			return err
+		}
+		opts.Export = f
+		if c.config.Output == "yaml" {
+			opts.ExportFormat = snapshot.FormatYAML
+		}
+	}
+	impact, err := safedelete.Delete(api, orgID, c.flag("confirm"), opts)
+	if f != nil {
+		closeErr := f.Close()
+		if _, requested := err.(safedelete.DeleteError); err != nil && !requested {
+			// the deletion was not requested, the export is not needed
+			os.Remove(f.Name())
+			return err
+		}
+		// the export was synced before deleting, keep it even if closing failed
+		saveErr := os.Rename(f.Name(), file)
+		if saveErr == nil {
+			saveErr = closeErr
+		}
+		if saveErr != nil && err == nil {
+			err = fmt.Errorf("organization deleted but its export %s was not saved: %v", f.Name(), saveErr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return c.printOrgs(impact.Organization, impact.Organization)
+}
+
+func runMembersList(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	users, err := api.GetOrganizationUsers(args[0])
+	if err != nil {
+		return err
+	}
+	if users == nil {
+		users = []client.UserInOrganization{}
+	}
+	return c.printMembers(users, users...)
+}
+
+func runMembersGet(c *cli, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	user, err := api.GetOrganizationUserID(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	return c.printMembers(user, user)
+}
+
+func runMembersAdd(c *cli, args []string) error {
+	user := client.UserInOrganization{
+		Login: c.flag("login"),
+		Email: c.flag("email"),
+		Role:  c.flag("role"),
+	}
+	if len(args) != 1 || user.Login == "" {
+		return errUsage
+	}
+	user.OrgID = args[0]
+
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	user, err = api.CreateUserOrganization(args[0], user)
+	if err != nil {
+		return err
+	}
+	return c.printMembers(user, user)
+}
+
+func runMembersRemove(c *cli, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	user, err := api.DeleteOrganizationUser(args[1], args[0])
+	if err != nil {
+		return err
+	}
+	return c.printMembers(user, user)
+}
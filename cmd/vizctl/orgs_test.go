@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgsSafeDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vizctl")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tenant.json")
+
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	user := s.AddUser(client.User{Login: "alice", Email: "alice@example.com"})
+	s.AddMember(org.OrganizationID, user.UserID, client.RoleEditor)
+
+	code, stdout, stderr := runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", "1", "--preview")
+	assert.Equal(t, 0, code, stderr)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	assert.Equal(t, []string{
+		"organization tenant (1) will be deleted with 1 members and 0 dashboards",
+		"member alice (2): Editor",
+	}, lines[:2])
+	token := strings.TrimPrefix(lines[2], "confirmation token: ")
+	assert.Len(t, token, 12)
+
+	code, _, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", "1", "--confirm", "000000000000", "--export", path)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "confirmation does not match")
+	assert.Len(t, s.Organizations(), 1)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "no export left behind")
+
+	err = ioutil.WriteFile(path, []byte("previous backup"), 0600)
+	assert.Equal(t, nil, err)
+	code, _, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", "1", "--confirm", "000000000000", "--export", path)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "confirmation does not match")
+	previous, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "previous backup", string(previous), "existing file kept")
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1, "no temporary file left behind")
+
+	code, _, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", "1", "--preview", "--confirm", token)
+	assert.Equal(t, 2, code, stderr)
+
+	code, _, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", "1", "--confirm", token, "--export", path)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, []client.Org{}, s.Organizations())
+	exported, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	assert.Contains(t, string(exported), `"name": "tenant"`)
+
+	other := s.AddOrganization("other")
+	s.InjectFault(visualizationtest.Fault{Method: "DELETE", Path: "/admin/organizations/" + other.OrganizationID, Status: http.StatusBadGateway})
+	code, stdout, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", other.OrganizationID, "--preview")
+	assert.Equal(t, 0, code, stderr)
+	lines = strings.Split(stdout, "\n")
+	token = strings.TrimPrefix(lines[1], "confirmation token: ")
+	os.Remove(path)
+	code, _, stderr = runCLI(nil, "--url", s.URL, "--token", "token", "orgs", "delete", other.OrganizationID, "--confirm", token, "--export", path)
+	assert.Equal(t, 1, code, stderr)
+	_, err = os.Stat(path)
+	assert.Equal(t, nil, err, "export kept when the deletion was requested")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// print writes value in the configured output format.
+// Tables are built from headers and rows, JSON and YAML from value itself.
+func (c *cli) print(value interface{}, headers []string, rows [][]string) error {
+	err := c.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	switch c.config.Output {
+	case "table", "":
+		tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(c.stdout, string(data))
+		return err
+
+	case "yaml":
+		data, err := toYAML(value)
+		if err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(data)
+		return err
+	}
+	return fmt.Errorf("unknown output format %q", c.config.Output)
+}
+
+// toYAML marshals value with the field names and order of its JSON encoding
+func toYAML(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var ordered interface{}
+	switch {
+	case len(data) > 0 && data[0] == '[':
+		var items []yaml.MapSlice
+		err = yaml.Unmarshal(data, &items)
+		ordered = items
+	default:
+		var item yaml.MapSlice
+		err = yaml.Unmarshal(data, &item)
+		ordered = item
+	}
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(ordered)
+}
+
+// createTemp creates a temporary file in the directory of path, renamed to
+// path once completely written so that a failure never touches path
+func createTemp(path string) (*os.File, error) {
+	return ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+}
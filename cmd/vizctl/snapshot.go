@@ -0,0 +1,143 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kbhonagiri16/visualization-client/snapshot"
+)
+
+func snapshotCommand() *command {
+	return &command{
+		name:        "snapshot",
+		description: "Export or restore organizations, users and memberships",
+		subcommands: []*command{
+			{
+				name:        "export",
+				args:        "[--file <file>]",
+				description: "Write a snapshot, in YAML with -o yaml and JSON otherwise",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("file", "-", "snapshot file, - for stdout")
+				},
+				run: runSnapshotExport,
+			},
+			{
+				name:        "restore",
+				args:        "<file> [--dry-run]",
+				description: "Create the organizations, users and memberships of a snapshot missing in the API",
+				flags: func(fs *flag.FlagSet) {
+					fs.Bool("dry-run", false, "report what would be created without changing anything")
+				},
+				run: runSnapshotRestore,
+			},
+			{
+				name:        "diff",
+				args:        "<from-file> <to-file>",
+				description: "Show the organizations, users, members and roles changed between two snapshots",
+				run:         runSnapshotDiff,
+			},
+		},
+	}
+}
+
+func runSnapshotExport(c *cli, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	snap, err := snapshot.Export(api, snapshot.Options{})
+	if err != nil {
+		return err
+	}
+	format := snapshot.FormatJSON
+	if c.config.Output == "yaml" {
+		format = snapshot.FormatYAML
+	}
+
+	file := c.flag("file")
+	if file == "-" {
+		return snap.Write(c.stdout, format)
+	}
+	// write next to the previous backup, replaced only once complete
+	f, err := createTemp(file)
+	if err != nil {
+		return err
+	}
+	err = snap.Write(f, format)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+func runSnapshotRestore(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	snap, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+
+	report, err := snapshot.Restore(api, snap, snapshot.RestoreOptions{DryRun: c.flag("dry-run") == "true"})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(report.Results))
+	for _, result := range report.Results {
+		rows = append(rows, []string{string(result.Kind), result.Name, string(result.Status), result.Reason})
+	}
+	return c.print(report, []string{"KIND", "NAME", "STATUS", "REASON"}, rows)
+}
+
+// readSnapshot reads the snapshot stored in path
+func readSnapshot(path string) (snapshot.Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	defer f.Close()
+	snap, err := snapshot.Read(f)
+	if err != nil {
+		return snapshot.Snapshot{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return snap, nil
+}
+
+func runSnapshotDiff(c *cli, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	from, err := readSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := readSnapshot(args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := snapshot.Compare(from, to)
+	err = c.loadConfig()
+	if err != nil {
+		return err
+	}
+	if c.config.Output == "table" || c.config.Output == "" {
+		return diff.Write(c.stdout, snapshot.FormatText)
+	}
+	return c.print(diff, nil, nil)
+}
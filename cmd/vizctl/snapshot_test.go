@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vizctl")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.yaml")
+
+	source := visualizationtest.NewServer("token")
+	defer source.Close()
+	org := source.AddOrganization("tenant")
+	user := source.AddUser(client.User{Login: "alice", Email: "alice@example.com", Name: "Alice"})
+	source.AddMember(org.OrganizationID, user.UserID, client.RoleEditor)
+
+	code, stdout, stderr := runCLI(nil, "--url", source.URL, "--token", "token", "snapshot", "export", "-o", "yaml")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, `version: 1
+organizations:
+- name: tenant
+  members:
+  - login: alice
+    role: Editor
+users:
+- login: alice
+  email: alice@example.com
+  name: Alice
+`, stdout)
+
+	err = ioutil.WriteFile(path, []byte("previous backup"), 0600)
+	assert.Equal(t, nil, err)
+	source.InjectFault(visualizationtest.Fault{Method: "GET", Path: "/admin/users", Status: http.StatusBadGateway, Times: 1})
+	code, _, _ = runCLI(nil, "--url", source.URL, "--token", "token", "snapshot", "export", "--file", path)
+	assert.Equal(t, 1, code)
+	previous, err := ioutil.ReadFile(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "previous backup", string(previous), "previous backup kept")
+
+	code, _, stderr = runCLI(nil, "--url", source.URL, "--token", "token", "snapshot", "export", "--file", path)
+	assert.Equal(t, 0, code, stderr)
+	files, _ := ioutil.ReadDir(dir)
+	assert.Len(t, files, 1, "no temporary file left behind")
+
+	target := visualizationtest.NewServer("token")
+	defer target.Close()
+	code, stdout, stderr = runCLI(nil, "--url", target.URL, "--token", "token", "snapshot", "restore", path, "--dry-run")
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "KIND          NAME          STATUS   REASON\n"+
+		"organization  tenant        created  \n"+
+		"user          alice         created  \n"+
+		"member        tenant/alice  created  as Editor\n", stdout)
+	assert.Equal(t, []client.Org{}, target.Organizations())
+
+	code, _, stderr = runCLI(nil, "--url", target.URL, "--token", "token", "snapshot", "restore", path)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, []client.UserInOrganization{{OrgID: "1", UserID: "2", Login: "alice", Email: "alice@example.com", Role: client.RoleEditor}}, target.Members("1"))
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vizctl")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+
+	from := filepath.Join(dir, "from.yaml")
+	to := filepath.Join(dir, "to.json")
+	ioutil.WriteFile(from, []byte("version: 1\norganizations:\n- name: main\n  members:\n  - login: alice\n    role: Viewer\nusers:\n- login: alice\n"), 0600)
+	ioutil.WriteFile(to, []byte(`{"version":1,"organizations":[{"name":"main","members":[{"login":"alice","role":"Admin"}]}],"users":[{"login":"alice"}]}`), 0600)
+
+	code, stdout, stderr := runCLI(nil, "snapshot", "diff", from, to)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "~ member main/alice Viewer -> Admin\n0 added, 0 removed, 1 role changes.\n", stdout)
+
+	code, stdout, _ = runCLI(nil, "-o", "json", "snapshot", "diff", to, to)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "{\n  \"changes\": []\n}\n", stdout)
+
+	code, _, stderr = runCLI(nil, "snapshot", "diff", from, filepath.Join(dir, "missing"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing")
+}
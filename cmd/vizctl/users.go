@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+func usersCommand() *command {
+	return &command{
+		name:        "users",
+		description: "Manage users",
+		subcommands: []*command{
+			{
+				name:        "list",
+				description: "List users",
+				run:         runUsersList,
+			},
+			{
+				name:        "get",
+				args:        "<user-id> | --name <name>",
+				description: "Show a user by ID or by name",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("name", "", "user name")
+				},
+				run:            runUsersGet,
+				complete:       []string{completeUserID},
+				flagCompletion: map[string]string{"name": completeUserName},
+			},
+			{
+				name:        "create",
+				args:        "--login <login> --email <email> [--name <name>] [--password <password>|-]",
+				description: "Create a user",
+				flags: func(fs *flag.FlagSet) {
+					fs.String("login", "", "user login")
+					fs.String("email", "", "user email")
+					fs.String("name", "", "user name, the login when empty")
+					fs.String("password", "", "user password, - to read it from stdin")
+				},
+				run: runUsersCreate,
+			},
+			{
+				name:        "delete",
+				args:        "<user-id>",
+				description: "Delete a user",
+				run:         runUsersDelete,
+				complete:    []string{completeUserID},
+			},
+			{
+				name:        "orgs",
+				args:        "<user-id>",
+				description: "List the organizations of a user and the user's role in each",
+				run:         runUsersOrgs,
+				complete:    []string{completeUserID},
+			},
+		},
+	}
+}
+
+func (c *cli) printUsers(value interface{}, users ...client.User) error {
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		rows = append(rows, []string{user.UserID, user.Login, user.Name, user.Email})
+	}
+	return c.print(value, []string{"ID", "LOGIN", "NAME", "EMAIL"}, rows)
+}
+
+func runUsersList(c *cli, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	users, err := api.GetUsers()
+	if err != nil {
+		return err
+	}
+	if users == nil {
+		users = []client.User{}
+	}
+	return c.printUsers(users, users...)
+}
+
+func runUsersGet(c *cli, args []string) error {
+	name := c.flag("name")
+	if (name == "") == (len(args) != 1) || len(args) > 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var user client.User
+	if name != "" {
+		user, err = api.GetUserName(name)
+	} else {
+		user, err = api.GetUserID(args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return c.printUsers(user, user)
+}
+
+func runUsersCreate(c *cli, args []string) error {
+	user := client.User{
+		Login: c.flag("login"),
+		Email: c.flag("email"),
+		Name:  c.flag("name"),
+	}
+	if len(args) != 0 || user.Login == "" || user.Email == "" {
+		return errUsage
+	}
+	if user.Name == "" {
+		user.Name = user.Login
+	}
+
+	password, err := readPassword(c.flag("password"))
+	if err != nil {
+		return err
+	}
+	user.Password = password
+
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+	user, err = api.CreateUser(user)
+	if err != nil {
+		return err
+	}
+	return c.printUsers(user, user)
+}
+
+func runUsersDelete(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	user, err := api.DeleteUser(args[0])
+	if err != nil {
+		return err
+	}
+	return c.printUsers(user, user)
+}
+
+func runUsersOrgs(c *cli, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	orgs, err := api.GetUserOrganizations(args[0])
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(orgs))
+	for _, org := range orgs {
+		rows = append(rows, []string{org.OrgID, org.Name, org.Role})
+	}
+	return c.print(orgs, []string{"ID", "NAME", "ROLE"}, rows)
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+)
+
+// maxValidators bounds the number of polls and of ETags remembered
+const maxValidators = 1024
+
+// validator of a polled GET response and the body it validates
+type validator struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// validators remembers the validators of the GET responses by URL, safe for
+// concurrent use.
+// The *IfModified methods keep the validators and the last body of their
+// polls apart, so that the other reads of the same URLs, such as the one
+// done by CreateOrganization, do not hide a change from the next poll.
+// The other reads only keep the ETag, sent with If-Match by the deletions.
+type validators struct {
+	mu    sync.Mutex
+	polls map[string]validator
+	etags map[string]string
+}
+
+func newValidators() *validators {
+	return &validators{polls: map[string]validator{}, etags: map[string]string{}}
+}
+
+func (s *validators) poll(url string) (validator, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	found, ok := s.polls[url]
+	return found, ok
+}
+
+func (s *validators) setPoll(url string, found validator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.polls[url]; !ok && len(s.polls) >= maxValidators {
+		for evicted := range s.polls {
+			delete(s.polls, evicted)
+			break
+		}
+	}
+	s.polls[url] = found
+}
+
+func (s *validators) etag(url string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.etags[url]
+}
+
+func (s *validators) setETag(url string, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.etags[url]; !ok && len(s.etags) >= maxValidators {
+		for evicted := range s.etags {
+			delete(s.etags, evicted)
+			break
+		}
+	}
+	s.etags[url] = etag
+}
+
+func (s *validators) forget(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.polls, url)
+	delete(s.etags, url)
+}
+
+// notModified body of a 304 answer, the body remembered with the validator
+type notModified struct {
+	*bytes.Reader
+}
+
+type pollingKey struct{}
+
+// polling marks the GET requests sent within ctx as polls of a *IfModified method
+func polling(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pollingKey{}, true)
+}
+
+func isPolling(ctx context.Context) bool {
+	polled, _ := ctx.Value(pollingKey{}).(bool)
+	return polled
+}
+
+// conditionalHeaders adds the validators remembered for url to request.
+// Polls are sent with If-None-Match and If-Modified-Since, DELETE requests
+// with If-Match.
+func (v *VisualizationClient) conditionalHeaders(ctx context.Context, request *http.Request, url string) {
+	if v.validators == nil {
+		return
+	}
+	switch {
+	case request.Method == "GET" && isPolling(ctx):
+		found, ok := v.validators.poll(url)
+		if !ok {
+			return
+		}
+		if found.etag != "" {
+			request.Header.Set("If-None-Match", found.etag)
+		}
+		if found.lastModified != "" {
+			request.Header.Set("If-Modified-Since", found.lastModified)
+		}
+	case request.Method == "DELETE":
+		if etag := v.validators.etag(url); etag != "" {
+			request.Header.Set("If-Match", etag)
+		}
+	}
+}
+
+// conditionalResponse remembers or forgets the validators of url after a
+// response. It returns the remembered body when the response is a 304 to a
+// poll, and an error when it is a 304 to any other request.
+func (v *VisualizationClient) conditionalResponse(ctx context.Context, method string, url string, response *http.Response, data []byte) (*notModified, error) {
+	if response.StatusCode == http.StatusNotModified {
+		if v.validators != nil && method == "GET" && isPolling(ctx) {
+			if found, ok := v.validators.poll(url); ok {
+				return &notModified{bytes.NewReader(found.body)}, nil
+			}
+		}
+		return nil, VisualizationError{code: "304", message: "Not Modified",
+			description: "Not modified answered without a remembered response"}
+	}
+	if v.validators == nil {
+		return nil, nil
+	}
+	switch {
+	case method == "GET" && response.StatusCode == http.StatusOK && isPolling(ctx):
+		found := validator{
+			etag:         response.Header.Get("ETag"),
+			lastModified: response.Header.Get("Last-Modified"),
+			body:         data,
+		}
+		if found.etag != "" || found.lastModified != "" {
+			v.validators.setPoll(url, found)
+		}
+	case method == "GET" && response.StatusCode == http.StatusOK:
+		if etag := response.Header.Get("ETag"); etag != "" {
+			v.validators.setETag(url, etag)
+		}
+	case method != "GET":
+		v.validators.forget(url)
+	}
+	return nil, nil
+}
+
+// GetUsersIfModified returns list of users and whether it changed since the
+// previous call. Without WithConditionalRequests the list is always modified.
+func (v *VisualizationClient) GetUsersIfModified() (user []User, modified bool, err error) {
+	return v.GetUsersIfModifiedContext(context.Background())
+}
+
+// GetUsersIfModifiedContext is GetUsersIfModified within ctx
+func (v *VisualizationClient) GetUsersIfModifiedContext(ctx context.Context) (user []User, modified bool, err error) {
+	return v.getUsers(polling(ctx))
+}
+
+// GetOrganizationsIfModified returns list of organizations and whether it
+// changed since the previous call. Without WithConditionalRequests the list
+// is always modified.
+func (v *VisualizationClient) GetOrganizationsIfModified() (org []Org, modified bool, err error) {
+	return v.GetOrganizationsIfModifiedContext(context.Background())
+}
+
+// GetOrganizationsIfModifiedContext is GetOrganizationsIfModified within ctx
+func (v *VisualizationClient) GetOrganizationsIfModifiedContext(ctx context.Context) (org []Org, modified bool, err error) {
+	return v.getOrganizations(polling(ctx))
+}
+
+// GetOrganizationUsersIfModified gets Users in Organisation and whether they
+// changed since the previous call. Without WithConditionalRequests the list
+// is always modified.
+func (v *VisualizationClient) GetOrganizationUsersIfModified(ID string) (org []UserInOrganization, modified bool, err error) {
+	return v.GetOrganizationUsersIfModifiedContext(context.Background(), ID)
+}
+
+// GetOrganizationUsersIfModifiedContext is GetOrganizationUsersIfModified within ctx
+func (v *VisualizationClient) GetOrganizationUsersIfModifiedContext(ctx context.Context, ID string) (org []UserInOrganization, modified bool, err error) {
+	return v.getOrganizationUsers(polling(ctx), ID)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	var headers []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt","token":{"expiresAt":"2999-01-01T00:00:00Z"}}`)
+			return
+		}
+		headers = append(headers, r.Header)
+		switch {
+		case r.URL.Path == "/admin/organizations" && r.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+		case r.URL.Path == "/admin/organizations":
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, `[{"organizationID":"1","name":"main"}]`)
+		case r.URL.Path == "/admin/users" && r.Header.Get("If-Modified-Since") != "":
+			w.WriteHeader(http.StatusNotModified)
+		case r.URL.Path == "/admin/users":
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			fmt.Fprint(w, `[{"userID":"2","login":"alice"}]`)
+		case r.Method == "GET":
+			w.Header().Set("ETag", `"u2"`)
+			fmt.Fprint(w, `{"userID":"2","login":"alice"}`)
+		case r.Header.Get("If-Match") != `"u2"`:
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `{}`)
+		default:
+			fmt.Fprint(w, `{"userID":"2","login":"alice"}`)
+		}
+	}))
+	defer ts.Close()
+
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithConditionalRequests())
+
+	orgs, modified, err := client.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.True(t, modified, "first poll")
+	assert.Equal(t, []Org{{OrganizationID: "1", Name: "main"}}, orgs)
+
+	orgs, modified, err = client.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.False(t, modified, "304 answered")
+	assert.Equal(t, []Org{{OrganizationID: "1", Name: "main"}}, orgs, "remembered result")
+	assert.Equal(t, `"v1"`, headers[1].Get("If-None-Match"))
+
+	org, err := client.GetOrganizationName("main")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1", org.OrganizationID)
+	assert.Equal(t, "", headers[2].Get("If-None-Match"), "other reads are not conditional")
+
+	client.GetUsers()
+	users, modified, err := client.GetUsersIfModified()
+	assert.Equal(t, nil, err)
+	assert.True(t, modified, "first poll, whatever the other reads")
+	assert.Equal(t, "", headers[4].Get("If-Modified-Since"))
+	users, modified, err = client.GetUsersIfModified()
+	assert.Equal(t, nil, err)
+	assert.False(t, modified)
+	assert.Equal(t, "alice", users[0].Login)
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", headers[5].Get("If-Modified-Since"))
+
+	_, err = client.DeleteUser("2")
+	assert.Equal(t, "", headers[6].Get("If-Match"), "no validator without a previous read")
+	assert.Equal(t, "ERROR: Provided resource was modified since it was read", err.Error())
+
+	client.GetUserID("2")
+	_, err = client.DeleteUser("2")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `"u2"`, headers[8].Get("If-Match"))
+}
+
+func TestPollNotHiddenByOtherReads(t *testing.T) {
+	var mu sync.Mutex
+	orgs := []Org{{OrganizationID: "1", Name: "main"}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt","token":{"expiresAt":"2999-01-01T00:00:00Z"}}`)
+			return
+		}
+		if r.Method == "POST" {
+			orgs = append(orgs, Org{OrganizationID: fmt.Sprint(len(orgs) + 1), Name: "tenant"})
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		tag := fmt.Sprintf(`"v%d"`, len(orgs))
+		w.Header().Set("ETag", tag)
+		if r.Header.Get("If-None-Match") == tag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		json.NewEncoder(w).Encode(orgs)
+	}))
+	defer ts.Close()
+
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithConditionalRequests())
+	_, modified, err := client.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.True(t, modified)
+
+	_, err = client.CreateOrganization(Org{Name: "tenant"})
+	assert.Equal(t, nil, err)
+
+	found, modified, err := client.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.True(t, modified, "creation seen by the poll")
+	assert.Equal(t, 2, len(found))
+
+	_, modified, err = client.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.False(t, modified)
+}
+
+func TestNotModifiedWithoutValidator(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt","token":{"expiresAt":"2999-01-01T00:00:00Z"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer ts.Close()
+
+	for _, options := range [][]Option{nil, {WithConditionalRequests()}} {
+		client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", options...)
+		_, err := client.GetOrganizations()
+		assert.Equal(t, "ERROR: Not modified answered without a remembered response", err.Error())
+		_, _, err = client.GetOrganizationsIfModified()
+		assert.Equal(t, "ERROR: Not modified answered without a remembered response", err.Error())
+	}
+}
+
+func TestValidatorsBounded(t *testing.T) {
+	validators := newValidators()
+	for i := 0; i < maxValidators+10; i++ {
+		validators.setPoll(fmt.Sprint(i), validator{etag: "e", body: []byte("[]")})
+		validators.setETag(fmt.Sprint(i), "e")
+	}
+	assert.Equal(t, maxValidators, len(validators.polls))
+	assert.Equal(t, maxValidators, len(validators.etags))
+	_, ok := validators.poll(fmt.Sprint(maxValidators + 9))
+	assert.True(t, ok, "latest kept")
+}
+
+func TestUnconditionalRequests(t *testing.T) {
+	var headers []http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt","token":{"expiresAt":"2999-01-01T00:00:00Z"}}`)
+			return
+		}
+		headers = append(headers, r.Header)
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[]`)
+	}))
+	defer ts.Close()
+
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token")
+
+	for i := 0; i < 2; i++ {
+		_, modified, err := client.GetOrganizationUsersIfModified("1")
+		assert.Equal(t, nil, err)
+		assert.True(t, modified, "always modified by default")
+	}
+	assert.Equal(t, 2, len(headers))
+	assert.Equal(t, "", headers[1].Get("If-None-Match"))
+}
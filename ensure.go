@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UserDiffersError returned by EnsureUser when the existing user differs
+// from the wanted one: the API cannot update users
+type UserDiffersError struct {
+	User   User
+	Fields []string
+}
+
+// Error generate a error message.
+func (e UserDiffersError) Error() string {
+	return fmt.Sprintf("user %s exists with a different %s, which the API cannot update", e.User.Login, strings.Join(e.Fields, " and "))
+}
+
+// RoleChangeError reports a role change, by EnsureOrganizationUser or a
+// reconciler, that failed after removing the user from the organization. The previous role is
+// restored unless RestoreErr is set, in which case the user is no longer a
+// member of the organization.
+type RoleChangeError struct {
+	// User membership before the change
+	User       UserInOrganization
+	Role       string
+	Err        error
+	RestoreErr error
+}
+
+// Error generate a error message.
+func (e RoleChangeError) Error() string {
+	if e.RestoreErr != nil {
+		return fmt.Sprintf("changing the role of %s failed: %v; restoring %s failed, the user is no longer a member: %v",
+			e.User.Login, e.Err, e.User.Role, e.RestoreErr)
+	}
+	return fmt.Sprintf("changing the role of %s failed, %s restored: %v", e.User.Login, e.User.Role, e.Err)
+}
+
+// Member reports whether the user is still a member of the organization
+func (e RoleChangeError) Member() bool {
+	return e.RestoreErr == nil
+}
+
+// EnsureOrganization returns the organization named org.Name, creating it
+// when missing, and reports whether it was created.
+// Organizations only have a name, so an existing one is never changed.
+func (v *VisualizationClient) EnsureOrganization(org Org) (existing Org, changed bool, err error) {
+	return v.EnsureOrganizationContext(context.Background(), org)
+}
+
+// EnsureOrganizationContext is EnsureOrganization within ctx
+func (v *VisualizationClient) EnsureOrganizationContext(ctx context.Context, org Org) (existing Org, changed bool, err error) {
+	ctx, span := v.startSpan(ctx, "EnsureOrganization")
+	defer func() { endSpan(span, err) }()
+
+	existing, err = v.getOrganizationName(ctx, org.Name)
+	if err != nil || existing.OrganizationID != "" {
+		return existing, false, err
+	}
+
+	existing, err = v.createOrganization(ctx, org)
+	if hasCode(err, "409") {
+		// created by someone else in between
+		existing, err = v.getOrganizationName(ctx, org.Name)
+		return existing, false, err
+	}
+	return existing, err == nil, err
+}
+
+// EnsureUser returns the user with the login user.Login, creating it when
+// missing, and reports whether it was created.
+// The API has no endpoint updating users: when the existing user has another
+// email, or another name while user.Name is set, a UserDiffersError is
+// returned along with the existing user. Passwords cannot be compared.
+func (v *VisualizationClient) EnsureUser(user User) (existing User, changed bool, err error) {
+	return v.EnsureUserContext(context.Background(), user)
+}
+
+// EnsureUserContext is EnsureUser within ctx
+func (v *VisualizationClient) EnsureUserContext(ctx context.Context, user User) (existing User, changed bool, err error) {
+	ctx, span := v.startSpan(ctx, "EnsureUser")
+	defer func() { endSpan(span, err) }()
+
+	existing, found, err := v.userLogin(ctx, user.Login)
+	if err != nil {
+		return User{}, false, err
+	}
+	if !found {
+		err = v.postUser(ctx, user)
+		if err != nil && !hasCode(err, "409") {
+			return User{}, false, err
+		}
+		created := err == nil
+		existing, found, err = v.userLogin(ctx, user.Login)
+		if err == nil && !found {
+			err = fmt.Errorf("user %s not found after its creation", user.Login)
+		}
+		if err != nil || created {
+			span.SetAttributes(Attribute{Key: AttributeUserID, Value: existing.UserID})
+			return existing, created, err
+		}
+	}
+	span.SetAttributes(Attribute{Key: AttributeUserID, Value: existing.UserID})
+
+	var fields []string
+	if user.Email != "" && user.Email != existing.Email {
+		fields = append(fields, "email")
+	}
+	if user.Name != "" && user.Name != existing.Name {
+		fields = append(fields, "name")
+	}
+	if fields != nil {
+		return existing, false, UserDiffersError{User: existing, Fields: fields}
+	}
+	return existing, false, nil
+}
+
+// userLogin returns the user with login and whether it exists
+func (v *VisualizationClient) userLogin(ctx context.Context, login string) (User, bool, error) {
+	users, _, err := v.getUsers(ctx)
+	if err != nil {
+		return User{}, false, err
+	}
+	for _, user := range users {
+		if user.Login == login {
+			return user, true, nil
+		}
+	}
+	return User{}, false, nil
+}
+
+// EnsureOrganizationUser makes user, found by UserID, Login or Email, a
+// member of the organization with user.Role, and reports whether anything
+// changed.
+// The API cannot update memberships, so a role is changed by removing the
+// user and adding it back with the new role: the user has no access in
+// between. When adding it back fails, the previous role is restored and a
+// RoleChangeError returned; changed is true when restoring failed too.
+func (v *VisualizationClient) EnsureOrganizationUser(OrgID string, user UserInOrganization) (member UserInOrganization, changed bool, err error) {
+	return v.EnsureOrganizationUserContext(context.Background(), OrgID, user)
+}
+
+// EnsureOrganizationUserContext is EnsureOrganizationUser within ctx
+func (v *VisualizationClient) EnsureOrganizationUserContext(ctx context.Context, OrgID string, user UserInOrganization) (member UserInOrganization, changed bool, err error) {
+	ctx, span := v.startSpan(ctx, "EnsureOrganizationUser",
+		Attribute{Key: AttributeOrgID, Value: OrgID}, Attribute{Key: AttributeUserID, Value: user.UserID})
+	defer func() { endSpan(span, err) }()
+
+	// an invalid user would be rejected only after removing its membership
+	err = user.Validate()
+	if err != nil {
+		return UserInOrganization{}, false, err
+	}
+	members, _, err := v.getOrganizationUsers(ctx, OrgID)
+	if err != nil {
+		return UserInOrganization{}, false, err
+	}
+	for _, existing := range members {
+		if (user.UserID == "" || existing.UserID != user.UserID) &&
+			(user.Login == "" || existing.Login != user.Login) &&
+			(user.Email == "" || existing.Email != user.Email) {
+			continue
+		}
+		if existing.Role == user.Role {
+			return existing, false, nil
+		}
+
+		_, err = v.deleteOrganizationUser(ctx, existing.UserID, OrgID)
+		if err != nil {
+			return existing, false, err
+		}
+		user.UserID = existing.UserID
+		member, err = v.createUserOrganization(ctx, OrgID, user)
+		if err != nil {
+			existing.Password = ""
+			_, restoreErr := v.createUserOrganization(ctx, OrgID, existing)
+			err = RoleChangeError{User: existing, Role: user.Role, Err: err, RestoreErr: restoreErr}
+			if restoreErr != nil {
+				return UserInOrganization{}, true, err
+			}
+			return existing, false, err
+		}
+		return member, true, nil
+	}
+
+	member, err = v.createUserOrganization(ctx, OrgID, user)
+	if err != nil {
+		return UserInOrganization{}, false, err
+	}
+	return member, true, nil
+}
@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureOrganization(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	api := s.Client()
+
+	org, changed, err := api.EnsureOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, nil, err)
+	assert.True(t, changed)
+	assert.Equal(t, client.Org{OrganizationID: "1", Name: "tenant"}, org)
+
+	org, changed, err = api.EnsureOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, nil, err)
+	assert.False(t, changed, "re-run changes nothing")
+	assert.Equal(t, client.Org{OrganizationID: "1", Name: "tenant"}, org)
+	assert.Equal(t, 1, len(s.Organizations()))
+}
+
+func TestEnsureUser(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	api := s.Client()
+
+	user, changed, err := api.EnsureUser(client.User{Login: "alice", Email: "alice@example.com", Name: "Alice", Password: "secret"})
+	assert.Equal(t, nil, err)
+	assert.True(t, changed)
+	assert.Equal(t, client.User{UserID: "1", Login: "alice", Email: "alice@example.com", Name: "Alice"}, user)
+
+	tests := []struct {
+		description string
+		user        client.User
+		expectedErr string
+	}{
+		{"same user", client.User{Login: "alice", Email: "alice@example.com", Name: "Alice"}, ""},
+		{"name not given", client.User{Login: "alice", Email: "alice@example.com"}, ""},
+		{"other name", client.User{Login: "alice", Email: "alice@example.com", Name: "Alice Smith"},
+			"user alice exists with a different name, which the API cannot update"},
+		{"other email and name", client.User{Login: "alice", Email: "alice@example.org", Name: "Alice Smith"},
+			"user alice exists with a different email and name, which the API cannot update"},
+	}
+	for _, testCase := range tests {
+		user, changed, err := api.EnsureUser(testCase.user)
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+			_, ok := err.(client.UserDiffersError)
+			assert.True(t, ok, testCase.description)
+		}
+		assert.False(t, changed, testCase.description)
+		assert.Equal(t, "1", user.UserID, testCase.description)
+	}
+	assert.Equal(t, 1, len(s.Users()))
+}
+
+func TestEnsureOrganizationUser(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	s.AddUser(client.User{Login: "alice", Email: "alice@example.com"})
+	bob := s.AddUser(client.User{Login: "bob", Email: "bob@example.com"})
+	s.AddMember(org.OrganizationID, bob.UserID, client.RoleViewer)
+	api := s.Client()
+
+	tests := []struct {
+		description     string
+		user            client.UserInOrganization
+		expectedChanged bool
+		expectedRole    string
+	}{
+		{"added", client.UserInOrganization{Login: "alice", Role: client.RoleEditor}, true, client.RoleEditor},
+		{"unchanged", client.UserInOrganization{Login: "alice", Role: client.RoleEditor}, false, client.RoleEditor},
+		{"role changed, found by email", client.UserInOrganization{Email: "bob@example.com", Role: client.RoleAdmin}, true, client.RoleAdmin},
+		{"unchanged, found by ID", client.UserInOrganization{UserID: bob.UserID, Role: client.RoleAdmin}, false, client.RoleAdmin},
+	}
+	for _, testCase := range tests {
+		member, changed, err := api.EnsureOrganizationUser(org.OrganizationID, testCase.user)
+		assert.Equal(t, nil, err, testCase.description)
+		assert.Equal(t, testCase.expectedChanged, changed, testCase.description)
+		assert.Equal(t, testCase.expectedRole, member.Role, testCase.description)
+	}
+	assert.Equal(t, []string{"alice:Editor", "bob:Admin"}, roles(s, org.OrganizationID))
+
+	s.ResetRequests()
+	member, changed, err := api.EnsureOrganizationUser(org.OrganizationID, client.UserInOrganization{UserID: bob.UserID, Role: "viewer "})
+	assert.Equal(t, `invalid payload: role "viewer " is not one of Viewer, Editor, Admin`, err.Error())
+	assert.False(t, changed)
+	assert.Equal(t, client.UserInOrganization{}, member)
+	assert.Empty(t, s.Requests(), "nothing removed for an invalid role")
+	assert.Equal(t, []string{"alice:Editor", "bob:Admin"}, roles(s, org.OrganizationID))
+
+	// adding bob back with the new role fails once
+	s.InjectFault(visualizationtest.Fault{Method: "POST", Path: "/admin/organizations/1/users", Status: http.StatusInternalServerError, Times: 1})
+	member, changed, err = api.EnsureOrganizationUser(org.OrganizationID, client.UserInOrganization{Login: "bob", Role: client.RoleViewer})
+	assert.Contains(t, err.Error(), "changing the role of bob failed, Admin restored")
+	changeErr, ok := err.(client.RoleChangeError)
+	assert.True(t, ok)
+	assert.True(t, changeErr.Member())
+	assert.Equal(t, client.RoleViewer, changeErr.Role)
+	assert.False(t, changed)
+	assert.Equal(t, client.RoleAdmin, member.Role)
+	assert.Equal(t, []string{"alice:Editor", "bob:Admin"}, roles(s, org.OrganizationID))
+
+	// adding bob back fails, and so does restoring his role
+	s.InjectFault(visualizationtest.Fault{Method: "POST", Path: "/admin/organizations/1/users", Status: http.StatusInternalServerError, Times: 2})
+	member, changed, err = api.EnsureOrganizationUser(org.OrganizationID, client.UserInOrganization{Login: "bob", Role: client.RoleViewer})
+	changeErr, ok = err.(client.RoleChangeError)
+	assert.True(t, ok)
+	assert.False(t, changeErr.Member(), "no longer a member")
+	assert.Equal(t, client.RoleAdmin, changeErr.User.Role, "previous role reported")
+	assert.Contains(t, err.Error(), "the user is no longer a member")
+	assert.True(t, changed)
+	assert.Equal(t, client.UserInOrganization{}, member)
+	assert.Equal(t, []string{"alice:Editor"}, roles(s, org.OrganizationID))
+}
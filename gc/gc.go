@@ -0,0 +1,252 @@
+// Package gc reports organizations and users nobody owns anymore and
+// optionally deletes them.
+package gc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// API is the part of VisualizationClient used by the collector
+type API interface {
+	GetOrganizations() ([]client.Org, error)
+	GetUsers() ([]client.User, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+	DeleteOrganization(ID string) (client.Org, error)
+	DeleteUser(ID string) (client.User, error)
+}
+
+// TenantSource lists the organization names external tenants expect to exist
+type TenantSource interface {
+	Tenants() ([]string, error)
+}
+
+// ErrConfirmation returned when the confirmation does not match the report
+var ErrConfirmation = errors.New("confirmation does not match the report")
+
+// ErrNoPrefix returned when Tenants is set without OrgPrefix: every
+// organization without tenant, including the ones not managed by the tenant
+// source, would be reported as unowned
+var ErrNoPrefix = errors.New("an organization prefix is required with a tenant source")
+
+// Options tune what is considered garbage
+type Options struct {
+	// Tenants enables the detection of organizations without tenant
+	Tenants TenantSource
+	// OrgPrefix restricts the tenant check to organizations carrying it,
+	// required with Tenants
+	OrgPrefix string
+	// IgnoreOrganizations are never reported (e.g. "Main Org.")
+	IgnoreOrganizations []string
+	// IgnoreLogins are never reported (e.g. "admin")
+	IgnoreLogins []string
+}
+
+// Report garbage found by Scan
+type Report struct {
+	OrphanUsers          []client.User `json:"orphanUsers"`
+	EmptyOrganizations   []client.Org  `json:"emptyOrganizations"`
+	UnownedOrganizations []client.Org  `json:"unownedOrganizations"`
+}
+
+// Empty reports whether nothing was found
+func (r Report) Empty() bool {
+	return len(r.OrphanUsers) == 0 && len(r.EmptyOrganizations) == 0 && len(r.UnownedOrganizations) == 0
+}
+
+// ConfirmationToken identifies the content of the report.
+// Collect only deletes when given the token of the report it deletes.
+func (r Report) ConfirmationToken() string {
+	hash := sha256.New()
+	for _, user := range r.OrphanUsers {
+		fmt.Fprintf(hash, "user:%s\n", user.UserID)
+	}
+	for _, org := range r.EmptyOrganizations {
+		fmt.Fprintf(hash, "empty:%s\n", org.OrganizationID)
+	}
+	for _, org := range r.UnownedOrganizations {
+		fmt.Fprintf(hash, "unowned:%s\n", org.OrganizationID)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// Write prints the report for humans
+func (r Report) Write(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintln(w, "No garbage found.")
+		return err
+	}
+
+	for _, user := range r.OrphanUsers {
+		fmt.Fprintf(w, "user %s (%s): no organization membership\n", user.Login, user.UserID)
+	}
+	for _, org := range r.EmptyOrganizations {
+		fmt.Fprintf(w, "organization %s (%s): no members\n", org.Name, org.OrganizationID)
+	}
+	for _, org := range r.UnownedOrganizations {
+		fmt.Fprintf(w, "organization %s (%s): no matching tenant\n", org.Name, org.OrganizationID)
+	}
+	_, err := fmt.Fprintf(w, "confirmation token: %s\n", r.ConfirmationToken())
+	return err
+}
+
+type byOrgID []client.Org
+
+func (o byOrgID) Len() int           { return len(o) }
+func (o byOrgID) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o byOrgID) Less(i, j int) bool { return o[i].OrganizationID < o[j].OrganizationID }
+
+type byUserID []client.User
+
+func (u byUserID) Len() int           { return len(u) }
+func (u byUserID) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u byUserID) Less(i, j int) bool { return u[i].UserID < u[j].UserID }
+
+// Scan walks organizations, memberships and users and reports the garbage
+func Scan(api API, opts Options) (report Report, err error) {
+	if opts.Tenants != nil && opts.OrgPrefix == "" {
+		return Report{}, ErrNoPrefix
+	}
+
+	orgs, err := api.GetOrganizations()
+	if err != nil {
+		return
+	}
+	users, err := api.GetUsers()
+	if err != nil {
+		return
+	}
+
+	var tenants map[string]bool
+	if opts.Tenants != nil {
+		var names []string
+		names, err = opts.Tenants.Tenants()
+		if err != nil {
+			return
+		}
+		tenants = map[string]bool{}
+		for _, name := range names {
+			tenants[name] = true
+		}
+	}
+
+	ignoredOrgs := map[string]bool{}
+	for _, name := range opts.IgnoreOrganizations {
+		ignoredOrgs[name] = true
+	}
+	ignoredLogins := map[string]bool{}
+	for _, login := range opts.IgnoreLogins {
+		ignoredLogins[login] = true
+	}
+
+	members := map[string]bool{}
+	for _, org := range orgs {
+		var orgUsers []client.UserInOrganization
+		orgUsers, err = api.GetOrganizationUsers(org.OrganizationID)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, user := range orgUsers {
+			members[user.UserID] = true
+		}
+
+		if ignoredOrgs[org.Name] {
+			continue
+		}
+		switch {
+		case tenants != nil && strings.HasPrefix(org.Name, opts.OrgPrefix) && !tenants[org.Name]:
+			report.UnownedOrganizations = append(report.UnownedOrganizations, org)
+		case len(orgUsers) == 0:
+			report.EmptyOrganizations = append(report.EmptyOrganizations, org)
+		}
+	}
+
+	for _, user := range users {
+		if !members[user.UserID] && !ignoredLogins[user.Login] {
+			report.OrphanUsers = append(report.OrphanUsers, user)
+		}
+	}
+
+	sort.Sort(byUserID(report.OrphanUsers))
+	sort.Sort(byOrgID(report.EmptyOrganizations))
+	sort.Sort(byOrgID(report.UnownedOrganizations))
+	return
+}
+
+// AuditRecord one deletion performed by Collect
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	ID     string    `json:"id"`
+	Name   string    `json:"name"`
+	Reason string    `json:"reason"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Collect scans again and deletes everything reported, returning the report.
+// confirm must be the ConfirmationToken of the report shown to the user: when
+// anything changed since, such as an organization gaining members, nothing is
+// deleted and ErrConfirmation is returned. An audit record is written to
+// audit as a JSON line for each deletion, successful or not.
+// Collect keeps going after a failed deletion and returns the first error.
+func Collect(api API, opts Options, confirm string, audit io.Writer) (report Report, err error) {
+	if audit == nil {
+		return Report{}, errors.New("an audit writer is required")
+	}
+	report, err = Scan(api, opts)
+	if err != nil {
+		return
+	}
+	if confirm != report.ConfirmationToken() {
+		return report, ErrConfirmation
+	}
+
+	enc := json.NewEncoder(audit)
+	record := func(record AuditRecord, deleteErr error) error {
+		record.Time = time.Now().UTC()
+		if deleteErr != nil {
+			record.Error = deleteErr.Error()
+			if err == nil {
+				err = deleteErr
+			}
+		}
+		return enc.Encode(record)
+	}
+
+	orgs := []struct {
+		orgs   []client.Org
+		reason string
+	}{
+		{report.UnownedOrganizations, "no matching tenant"},
+		{report.EmptyOrganizations, "no members"},
+	}
+	for _, group := range orgs {
+		for _, org := range group.orgs {
+			_, deleteErr := api.DeleteOrganization(org.OrganizationID)
+			auditErr := record(AuditRecord{Kind: "organization", ID: org.OrganizationID,
+				Name: org.Name, Reason: group.reason}, deleteErr)
+			if auditErr != nil {
+				return report, auditErr
+			}
+		}
+	}
+
+	for _, user := range report.OrphanUsers {
+		_, deleteErr := api.DeleteUser(user.UserID)
+		auditErr := record(AuditRecord{Kind: "user", ID: user.UserID,
+			Name: user.Login, Reason: "no organization membership"}, deleteErr)
+		if auditErr != nil {
+			return report, auditErr
+		}
+	}
+	return
+}
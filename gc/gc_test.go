@@ -0,0 +1,149 @@
+package gc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAPI struct {
+	orgs    []client.Org
+	users   []client.User
+	members map[string][]client.UserInOrganization
+	deleted []string
+	failOn  string
+}
+
+func (f *fakeAPI) GetOrganizations() ([]client.Org, error) {
+	return f.orgs, nil
+}
+
+func (f *fakeAPI) GetUsers() ([]client.User, error) {
+	return f.users, nil
+}
+
+func (f *fakeAPI) GetOrganizationUsers(ID string) ([]client.UserInOrganization, error) {
+	return f.members[ID], nil
+}
+
+func (f *fakeAPI) DeleteOrganization(ID string) (client.Org, error) {
+	f.deleted = append(f.deleted, "org "+ID)
+	if "org "+ID == f.failOn {
+		return client.Org{}, errors.New("boom")
+	}
+	return client.Org{}, nil
+}
+
+func (f *fakeAPI) DeleteUser(ID string) (client.User, error) {
+	f.deleted = append(f.deleted, "user "+ID)
+	return client.User{}, nil
+}
+
+type tenants []string
+
+func (t tenants) Tenants() ([]string, error) {
+	return t, nil
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		orgs: []client.Org{
+			{OrganizationID: "1", Name: "Main Org."},
+			{OrganizationID: "2", Name: "os-alpha"},
+			{OrganizationID: "3", Name: "os-deleted"},
+			{OrganizationID: "4", Name: "os-empty"},
+		},
+		users: []client.User{
+			{UserID: "1", Login: "admin"},
+			{UserID: "2", Login: "alice"},
+			{UserID: "3", Login: "bob"},
+		},
+		members: map[string][]client.UserInOrganization{
+			"2": {{OrgID: "2", UserID: "2", Login: "alice"}},
+			"3": {{OrgID: "3", UserID: "2", Login: "alice"}},
+		},
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        Options
+		expected    Report
+	}{
+		{
+			description: "without tenant source",
+			opts:        Options{IgnoreLogins: []string{"admin"}},
+			expected: Report{
+				OrphanUsers:        []client.User{{UserID: "3", Login: "bob"}},
+				EmptyOrganizations: []client.Org{{OrganizationID: "1", Name: "Main Org."}, {OrganizationID: "4", Name: "os-empty"}},
+			},
+		},
+		{
+			description: "with tenant source",
+			opts: Options{Tenants: tenants{"os-alpha", "os-empty"}, OrgPrefix: "os-",
+				IgnoreOrganizations: []string{"Main Org."}},
+			expected: Report{
+				OrphanUsers:          []client.User{{UserID: "1", Login: "admin"}, {UserID: "3", Login: "bob"}},
+				EmptyOrganizations:   []client.Org{{OrganizationID: "4", Name: "os-empty"}},
+				UnownedOrganizations: []client.Org{{OrganizationID: "3", Name: "os-deleted"}},
+			},
+		},
+	}
+	for _, testCase := range tests {
+		report, err := Scan(newFakeAPI(), testCase.opts)
+		assert.Equal(t, err, nil, "no error")
+		assert.Equal(t, testCase.expected, report, testCase.description)
+	}
+}
+
+func TestScanWithoutPrefix(t *testing.T) {
+	_, err := Scan(newFakeAPI(), Options{Tenants: tenants{"os-alpha"}})
+	assert.Equal(t, ErrNoPrefix, err, "every organization would be unowned")
+}
+
+func TestCollect(t *testing.T) {
+	api := newFakeAPI()
+	api.failOn = "org 4"
+	opts := Options{Tenants: tenants{"os-alpha"}, OrgPrefix: "os-", IgnoreLogins: []string{"admin"}}
+	report, err := Scan(api, opts)
+	assert.Equal(t, err, nil, "no error")
+
+	audit := &bytes.Buffer{}
+	_, err = Collect(api, opts, "wrong", audit)
+	assert.Equal(t, ErrConfirmation, err)
+	assert.Equal(t, 0, len(api.deleted), "nothing deleted without confirmation")
+
+	collected, err := Collect(api, opts, report.ConfirmationToken(), audit)
+	assert.Equal(t, report, collected)
+	assert.NotNil(t, err, "failed deletion reported")
+	assert.Equal(t, []string{"org 3", "org 4", "org 1", "user 3"}, api.deleted)
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	assert.Equal(t, 4, len(lines))
+	var record AuditRecord
+	err = json.Unmarshal([]byte(lines[1]), &record)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, "organization", record.Kind)
+	assert.Equal(t, "os-empty", record.Name)
+	assert.Equal(t, "boom", record.Error)
+}
+
+func TestCollectChangedSinceScan(t *testing.T) {
+	api := newFakeAPI()
+	opts := Options{IgnoreLogins: []string{"admin"}}
+	report, err := Scan(api, opts)
+	assert.Equal(t, err, nil, "no error")
+
+	api.members["4"] = []client.UserInOrganization{{OrgID: "4", UserID: "3", Login: "bob"}}
+	audit := &bytes.Buffer{}
+	_, err = Collect(api, opts, report.ConfirmationToken(), audit)
+	assert.Equal(t, ErrConfirmation, err)
+	assert.Equal(t, 0, len(api.deleted), "organization gaining members kept")
+	assert.Equal(t, "", audit.String())
+}
@@ -0,0 +1,227 @@
+// Package importer loads users listed in CSV or LDIF files into an
+// organization.
+//
+// Import is idempotent: existing users are not created again and existing
+// members are left with their role, so a file can be imported again after
+// fixing its failed rows.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/internal/outcome"
+)
+
+// API is the part of VisualizationClient used by the importer
+type API interface {
+	GetUsers() ([]client.User, error)
+	CreateUser(user client.User) (client.User, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+	CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error)
+}
+
+// Row user to import, Line is its position in the file
+type Row struct {
+	Line  int    `json:"line"`
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+}
+
+// Status outcome of a row
+type Status = outcome.Status
+
+// Outcomes of the rows
+const (
+	Created = outcome.Created
+	Skipped = outcome.Skipped
+	Failed  = outcome.Failed
+)
+
+// Result of a row.
+// Password is the one given to the user created for the row, to hand over.
+type Result struct {
+	Row      Row    `json:"row"`
+	Status   Status `json:"status"`
+	Reason   string `json:"reason"`
+	Password string `json:"password,omitempty"`
+}
+
+// Report results of an import, in the order of the rows
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Count returns the number of rows with status
+func (r Report) Count(status Status) int {
+	return outcome.Count(r.statuses(), status)
+}
+
+func (r Report) statuses() []Status {
+	statuses := make([]Status, len(r.Results))
+	for i, result := range r.Results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
+
+// Failed returns the results of the failed rows
+func (r Report) Failed() (failed []Result) {
+	for _, result := range r.Results {
+		if result.Status == Failed {
+			failed = append(failed, result)
+		}
+	}
+	return
+}
+
+// Write prints the report as a table followed by a summary
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tLOGIN\tSTATUS\tREASON")
+	for _, result := range r.Results {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", result.Row.Line, result.Row.Login, result.Status, result.Reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return outcome.WriteSummary(w, r.statuses())
+}
+
+// WriteJSON prints the report as JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Options of an import
+type Options struct {
+	// DefaultRole of the rows without role, client.RoleViewer when empty
+	DefaultRole string
+	// Password returns the password of a created user, random when nil.
+	// Either way it is reported in the Result of the row.
+	Password func(row Row) (string, error)
+	// DryRun reports what would be done without changing anything
+	DryRun bool
+}
+
+// roles valid roles by lower case name
+var roles = map[string]string{
+	"viewer": client.RoleViewer,
+	"editor": client.RoleEditor,
+	"admin":  client.RoleAdmin,
+}
+
+// Validate checks the row as client.User.Validate does and normalizes its role
+func (row *Row) Validate(defaultRole string) error {
+	row.Login = strings.TrimSpace(row.Login)
+	row.Email = strings.TrimSpace(row.Email)
+	row.Name = strings.TrimSpace(row.Name)
+	err := client.User{Login: row.Login, Email: row.Email, Name: row.Name}.Validate()
+	if err != nil {
+		return err
+	}
+	role := strings.TrimSpace(row.Role)
+	if role == "" {
+		role = defaultRole
+	}
+	normalized, ok := roles[strings.ToLower(role)]
+	if !ok {
+		return fmt.Errorf("invalid role %q", role)
+	}
+	row.Role = normalized
+	return nil
+}
+
+// randomPassword default password of the created users
+func randomPassword(row Row) (string, error) {
+	return outcome.RandomPassword()
+}
+
+// Import creates the users of rows missing in the API and adds them to the
+// organization with ID orgID. A failed row does not stop the others.
+// The error is only set when the current users or members cannot be listed.
+func Import(api API, orgID string, rows []Row, opts Options) (report Report, err error) {
+	if opts.DefaultRole == "" {
+		opts.DefaultRole = client.RoleViewer
+	}
+	if opts.Password == nil {
+		opts.Password = randomPassword
+	}
+
+	users, err := api.GetUsers()
+	if err != nil {
+		return
+	}
+	byLogin := map[string]client.User{}
+	for _, user := range users {
+		byLogin[user.Login] = user
+	}
+	members, err := api.GetOrganizationUsers(orgID)
+	if err != nil {
+		return
+	}
+	memberRoles := map[string]string{}
+	for _, member := range members {
+		memberRoles[member.Login] = member.Role
+	}
+
+	seen := map[string]int{}
+	for _, row := range rows {
+		report.Results = append(report.Results, importRow(api, orgID, row, opts, byLogin, memberRoles, seen))
+	}
+	return
+}
+
+// importRow imports a row, recording the created users and members
+func importRow(api API, orgID string, row Row, opts Options, byLogin map[string]client.User, memberRoles map[string]string, seen map[string]int) Result {
+	if err := row.Validate(opts.DefaultRole); err != nil {
+		return Result{Row: row, Status: Failed, Reason: err.Error()}
+	}
+	if line, ok := seen[row.Login]; ok {
+		return Result{Row: row, Status: Failed, Reason: fmt.Sprintf("duplicate of line %d", line)}
+	}
+	seen[row.Login] = row.Line
+
+	if role, ok := memberRoles[row.Login]; ok {
+		if role == row.Role {
+			return Result{Row: row, Status: Skipped, Reason: "already a member"}
+		}
+		return Result{Row: row, Status: Skipped, Reason: fmt.Sprintf("already a member as %s, role left unchanged", role)}
+	}
+
+	var done []string
+	var password string
+	if _, ok := byLogin[row.Login]; !ok {
+		if !opts.DryRun {
+			var err error
+			password, err = opts.Password(row)
+			if err != nil {
+				return Result{Row: row, Status: Failed, Reason: err.Error()}
+			}
+			user := client.User{Login: row.Login, Email: row.Email, Name: row.Name, Password: password}
+			if _, err := api.CreateUser(user); err != nil {
+				return Result{Row: row, Status: Failed, Reason: "create user: " + err.Error()}
+			}
+			byLogin[row.Login] = user
+		}
+		done = append(done, "user created")
+	}
+
+	if !opts.DryRun {
+		member := client.UserInOrganization{Login: row.Login, Role: row.Role}
+		if _, err := api.CreateUserOrganization(orgID, member); err != nil {
+			return Result{Row: row, Status: Failed, Reason: strings.Join(append(done, "add member: "+err.Error()), ", "), Password: password}
+		}
+		memberRoles[row.Login] = row.Role
+	}
+	done = append(done, "added as "+row.Role)
+	return Result{Row: row, Status: Created, Reason: strings.Join(done, ", "), Password: password}
+}
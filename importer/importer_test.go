@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"bytes"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedPassword(row Row) (string, error) {
+	return "secret-" + row.Login, nil
+}
+
+func TestImport(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	bob := s.AddUser(client.User{Login: "bob", Email: "bob@example.com", Name: "Bob"})
+	carol := s.AddUser(client.User{Login: "carol", Email: "carol@example.com", Name: "Carol"})
+	s.AddMember(org.OrganizationID, carol.UserID, client.RoleAdmin)
+
+	rows := []Row{
+		{Line: 2, Login: "alice", Email: "alice@example.com", Name: "Alice", Role: "editor"},
+		{Line: 3, Login: "bob", Email: "bob@example.com", Name: "Bob"},
+		{Line: 4, Login: "carol", Email: "carol@example.com", Name: "Carol", Role: "Viewer"},
+		{Line: 5, Login: "dave", Email: "dave.example.com", Name: "Dave"},
+		{Line: 6, Login: "erin", Email: "erin@example.com", Role: "owner"},
+		{Line: 7, Login: "alice", Email: "alice2@example.com"},
+		{Line: 8, Login: "frank", Email: "bob@example.com", Name: "Frank"},
+	}
+	report, err := Import(s.Client(), org.OrganizationID, rows, Options{Password: fixedPassword})
+	assert.Equal(t, nil, err)
+
+	expected := []struct {
+		status Status
+		reason string
+	}{
+		{Created, "user created, added as Editor"},
+		{Created, "added as Viewer"},
+		{Skipped, "already a member as Admin, role left unchanged"},
+		{Failed, `invalid payload: email "dave.example.com" is not a valid address`},
+		{Failed, `invalid role "owner"`},
+		{Failed, "duplicate of line 2"},
+		{Failed, "create user: ERROR: Provided Details to create exists"},
+	}
+	for i, result := range report.Results {
+		assert.Equal(t, expected[i].status, result.Status, result.Row.Login)
+		assert.Equal(t, expected[i].reason, result.Reason, result.Row.Login)
+	}
+	assert.Equal(t, "secret-alice", report.Results[0].Password, "password of the created user reported")
+	assert.Equal(t, "", report.Results[1].Password, "existing user")
+	assert.Equal(t, 2, report.Count(Created))
+	assert.Equal(t, 1, report.Count(Skipped))
+	assert.Equal(t, 4, len(report.Failed()))
+
+	members := s.Members(org.OrganizationID)
+	assert.Equal(t, 3, len(members))
+	assert.Equal(t, client.UserInOrganization{OrgID: org.OrganizationID, UserID: bob.UserID, Login: "bob", Email: "bob@example.com", Role: client.RoleViewer}, members[0])
+
+	again, err := Import(s.Client(), org.OrganizationID, rows[:3], Options{Password: fixedPassword})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, again.Count(Skipped), "import is idempotent")
+}
+
+func TestImportDryRun(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+
+	rows := []Row{{Line: 1, Login: "alice", Email: "alice@example.com"}}
+	report, err := Import(s.Client(), org.OrganizationID, rows, Options{DryRun: true, DefaultRole: client.RoleEditor})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Created, report.Results[0].Status)
+	assert.Equal(t, "user created, added as Editor", report.Results[0].Reason)
+	assert.Equal(t, []client.User{}, s.Users())
+	assert.Equal(t, []client.UserInOrganization{}, s.Members(org.OrganizationID))
+}
+
+func TestImportUnknownOrganization(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+
+	_, err := Import(s.Client(), "42", []Row{{Login: "alice"}}, Options{})
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+}
+
+func TestRowValidate(t *testing.T) {
+	tests := []struct {
+		description  string
+		row          Row
+		expectedErr  string
+		expectedRole string
+	}{
+		{"role normalized", Row{Login: " alice ", Role: "admin"}, "", client.RoleAdmin},
+		{"default role", Row{Login: "alice"}, "", client.RoleViewer},
+		{"no login", Row{Email: "alice@example.com"}, "invalid payload: login is required", ""},
+		{"login with a space", Row{Login: "alice smith"}, `invalid payload: login contains ' ', only letters, digits and . _ - @ + are allowed`, ""},
+		{"email with a name", Row{Login: "alice", Email: "Alice <alice@example.com>"}, `invalid payload: email "Alice <alice@example.com>" is not a valid address`, ""},
+		{"invalid role", Row{Login: "alice", Role: "owner"}, `invalid role "owner"`, ""},
+	}
+	for _, testCase := range tests {
+		row := testCase.row
+		err := row.Validate(client.RoleViewer)
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+			assert.Equal(t, testCase.expectedRole, row.Role, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+		}
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := Report{Results: []Result{
+		{Row: Row{Line: 2, Login: "alice"}, Status: Created, Reason: "added as Viewer"},
+		{Row: Row{Line: 3, Login: ""}, Status: Failed, Reason: "login is required"},
+	}}
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, report.Write(out))
+	assert.Equal(t, `LINE  LOGIN  STATUS   REASON
+2     alice  created  added as Viewer
+3            failed   login is required
+1 created, 0 skipped, 1 failed.
+`, out.String())
+}
@@ -0,0 +1,172 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumns columns of a CSV file without header, in order
+var csvColumns = []string{"login", "email", "name", "role"}
+
+// ReadCSV reads rows from CSV with the columns login, email, name and role.
+// A first line naming the columns sets their order; without it the columns
+// are in that order. The role column is optional. Blank lines and lines
+// starting with # are ignored; quoted fields cannot span lines. A leading
+// UTF-8 byte order mark, written by spreadsheets, is skipped.
+func ReadCSV(r io.Reader) (rows []Row, err error) {
+	columns := map[string]int{}
+	for i, name := range csvColumns {
+		columns[name] = i
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line, first := 1, true; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		reader := csv.NewReader(strings.NewReader(text))
+		reader.TrimLeadingSpace = true
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if first && isHeader(record) {
+			first = false
+			columns = map[string]int{}
+			for i, name := range record {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			continue
+		}
+		first = false
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+		rows = append(rows, Row{Line: line, Login: field("login"), Email: field("email"), Name: field("name"), Role: field("role")})
+	}
+	return rows, scanner.Err()
+}
+
+// isHeader reports whether record names the CSV columns
+func isHeader(record []string) bool {
+	for _, name := range record {
+		if strings.EqualFold(strings.TrimSpace(name), "login") {
+			return true
+		}
+	}
+	return false
+}
+
+// LDIFAttributes names the LDIF attributes holding the fields of a row
+type LDIFAttributes struct {
+	Login string
+	Email string
+	Name  string
+	// Role is optional, the rows get the default role of the import without it
+	Role string
+}
+
+// DefaultLDIFAttributes attributes of the usual inetOrgPerson entries
+var DefaultLDIFAttributes = LDIFAttributes{Login: "uid", Email: "mail", Name: "cn"}
+
+// ldifLine attribute line of an LDIF entry, unfolded
+type ldifLine struct {
+	number int
+	text   string
+}
+
+// ReadLDIF reads rows from the entries of an LDIF export.
+// Entries without the login attribute, like groups or organizational units,
+// are ignored. The first value of multi-valued attributes is used.
+func ReadLDIF(r io.Reader, attrs LDIFAttributes) (rows []Row, err error) {
+	var entry []ldifLine
+	flush := func() error {
+		row, ok, err := ldifRow(entry, attrs)
+		if err != nil {
+			return err
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+		entry = nil
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case text == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, " "):
+			if len(entry) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without attribute", number)
+			}
+			entry[len(entry)-1].text += text[1:]
+		default:
+			entry = append(entry, ldifLine{number: number, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ldifRow returns the row of an entry, ok is false for entries without login
+func ldifRow(entry []ldifLine, attrs LDIFAttributes) (row Row, ok bool, err error) {
+	values := map[string]string{}
+	for _, line := range entry {
+		colon := strings.Index(line.text, ":")
+		if colon <= 0 {
+			return row, false, fmt.Errorf("line %d: expected attribute: value", line.number)
+		}
+		name := strings.ToLower(line.text[:colon])
+		value := line.text[colon+1:]
+		if strings.HasPrefix(value, ":") {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return row, false, fmt.Errorf("line %d: %v", line.number, err)
+			}
+			value = string(decoded)
+		} else {
+			value = strings.TrimLeft(value, " ")
+		}
+		if _, seen := values[name]; !seen {
+			values[name] = value
+		}
+	}
+
+	value := func(attribute string) string {
+		if attribute == "" {
+			return ""
+		}
+		return values[strings.ToLower(attribute)]
+	}
+	login, ok := values[strings.ToLower(attrs.Login)]
+	if !ok || len(entry) == 0 {
+		return row, false, nil
+	}
+	row = Row{Line: entry[0].number, Login: login, Email: value(attrs.Email), Name: value(attrs.Name), Role: value(attrs.Role)}
+	return row, true, nil
+}
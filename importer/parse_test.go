@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    []Row
+	}{
+		{
+			description: "header sets the order",
+			input:       "Email,Login,Role\n# comment\n\nalice@example.com, alice, Editor\n",
+			expected:    []Row{{Line: 4, Login: "alice", Email: "alice@example.com", Role: "Editor"}},
+		},
+		{
+			description: "byte order mark before the header",
+			input:       "\ufeffemail,login\r\nalice@example.com,alice\r\n",
+			expected:    []Row{{Line: 2, Login: "alice", Email: "alice@example.com"}},
+		},
+		{
+			description: "byte order mark without header",
+			input:       "\ufeffalice,alice@example.com\n",
+			expected:    []Row{{Line: 1, Login: "alice", Email: "alice@example.com"}},
+		},
+		{
+			description: "no header",
+			input:       "alice,alice@example.com,\"Liddell, Alice\",Admin\nbob,bob@example.com,Bob\n",
+			expected: []Row{
+				{Line: 1, Login: "alice", Email: "alice@example.com", Name: "Liddell, Alice", Role: "Admin"},
+				{Line: 2, Login: "bob", Email: "bob@example.com", Name: "Bob"},
+			},
+		},
+	}
+	for _, testCase := range tests {
+		rows, err := ReadCSV(strings.NewReader(testCase.input))
+		assert.Equal(t, nil, err, testCase.description)
+		assert.Equal(t, testCase.expected, rows, testCase.description)
+	}
+
+	_, err := ReadCSV(strings.NewReader("alice,\"unterminated\n"))
+	assert.Contains(t, err.Error(), "line 1:")
+}
+
+func TestReadLDIF(t *testing.T) {
+	input := `version: 1
+
+# people
+dn: ou=people,dc=example,dc=com
+objectClass: organizationalUnit
+ou: people
+
+dn: uid=alice,ou=people,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: alice
+cn: Alice
+ Liddell
+mail: alice@example.com
+mail: alice@old.example.com
+employeeType: Editor
+
+dn: uid=bob,ou=people,dc=example,dc=com
+uid: bob
+cn:: QsO2Yg==
+mail: bob@example.com
+`
+	rows, err := ReadLDIF(strings.NewReader(input), DefaultLDIFAttributes)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Row{
+		{Line: 8, Login: "alice", Email: "alice@example.com", Name: "AliceLiddell"},
+		{Line: 17, Login: "bob", Email: "bob@example.com", Name: "Böb"},
+	}, rows)
+
+	attrs := DefaultLDIFAttributes
+	attrs.Role = "employeeType"
+	rows, _ = ReadLDIF(strings.NewReader(input), attrs)
+	assert.Equal(t, "Editor", rows[0].Role)
+
+	_, err = ReadLDIF(strings.NewReader("dn: uid=x\nbroken\n"), DefaultLDIFAttributes)
+	assert.Equal(t, "line 2: expected attribute: value", err.Error())
+}
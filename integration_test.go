@@ -0,0 +1,137 @@
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminRequests returns the requests of the server without the authentications
+func adminRequests(s *visualizationtest.Server) (requests []string) {
+	for _, request := range s.Requests() {
+		if request != "POST /auth/openstack" {
+			requests = append(requests, request)
+		}
+	}
+	return
+}
+
+func TestEndpoints(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	user := s.AddUser(client.User{Login: "alice", Name: "Alice", Email: "alice@example.com"})
+	s.AddMember(org.OrganizationID, user.UserID, client.RoleViewer)
+	api := s.Client()
+
+	tests := []struct {
+		description string
+		call        func() error
+		expected    []string
+	}{
+		{
+			description: "GetUsers",
+			call:        func() error { _, err := api.GetUsers(); return err },
+			expected:    []string{"GET /admin/users"},
+		},
+		{
+			description: "GetUserID",
+			call:        func() error { _, err := api.GetUserID(user.UserID); return err },
+			expected:    []string{"GET /admin/users/2"},
+		},
+		{
+			description: "GetOrganizations",
+			call:        func() error { _, err := api.GetOrganizations(); return err },
+			expected:    []string{"GET /admin/organizations"},
+		},
+		{
+			description: "GetOrganizationID",
+			call:        func() error { _, err := api.GetOrganizationID(org.OrganizationID); return err },
+			expected:    []string{"GET /admin/organizations/1"},
+		},
+		{
+			description: "GetOrganizationUsers",
+			call:        func() error { _, err := api.GetOrganizationUsers(org.OrganizationID); return err },
+			expected:    []string{"GET /admin/organizations/1/users"},
+		},
+		{
+			description: "CreateOrganization",
+			call:        func() error { _, err := api.CreateOrganization(client.Org{Name: "other"}); return err },
+			expected:    []string{"POST /admin/organizations", "GET /admin/organizations"},
+		},
+		{
+			description: "CreateUser",
+			call: func() error {
+				_, err := api.CreateUser(client.User{Login: "bob", Name: "Bob", Email: "bob@example.com"})
+				return err
+			},
+			expected: []string{"POST /admin/users", "GET /admin/users"},
+		},
+		{
+			description: "CreateUserOrganization",
+			call: func() error {
+				_, err := api.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "bob", Role: client.RoleEditor})
+				return err
+			},
+			expected: []string{"POST /admin/organizations/1/users"},
+		},
+		{
+			description: "DeleteOrganizationUser",
+			call:        func() error { _, err := api.DeleteOrganizationUser(user.UserID, org.OrganizationID); return err },
+			expected:    []string{"DELETE /admin/organizations/1/users/2"},
+		},
+		{
+			description: "DeleteUser",
+			call:        func() error { _, err := api.DeleteUser(user.UserID); return err },
+			expected:    []string{"DELETE /admin/users/2"},
+		},
+		{
+			description: "DeleteOrganization",
+			call:        func() error { _, err := api.DeleteOrganization(org.OrganizationID); return err },
+			expected:    []string{"DELETE /admin/organizations/1"},
+		},
+	}
+	for _, testCase := range tests {
+		s.ResetRequests()
+		err := testCase.call()
+		assert.Equal(t, err, nil, testCase.description)
+		assert.Equal(t, testCase.expected, adminRequests(s), testCase.description)
+	}
+
+	assert.Equal(t, []client.Org{{OrganizationID: "3", Name: "other"}}, s.Organizations())
+	assert.Equal(t, []client.User{{UserID: "4", Login: "bob", Name: "Bob", Email: "bob@example.com"}}, s.Users())
+}
+
+func TestNotFound(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	api := s.Client()
+
+	_, err := api.GetOrganizationID("42")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+	_, err = api.DeleteOrganizationUser("1", "42")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+}
+
+func TestConditionalPolling(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	api, _ := client.NewVisualizationClient(s.URL, http.Client{}, "token", client.WithConditionalRequests())
+
+	_, modified, err := api.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.True(t, modified)
+	orgs, modified, err := api.GetOrganizationsIfModified()
+	assert.Equal(t, nil, err)
+	assert.False(t, modified)
+	assert.Equal(t, []client.Org{org}, orgs)
+
+	s.AddOrganization("other")
+	orgs, modified, _ = api.GetOrganizationsIfModified()
+	assert.True(t, modified)
+	assert.Equal(t, 2, len(orgs))
+}
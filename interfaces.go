@@ -0,0 +1,46 @@
+package client
+
+// UserService operations on users
+type UserService interface {
+	GetUsers() ([]User, error)
+	GetUserName(name string) (User, error)
+	GetUserID(ID string) (User, error)
+	CreateUser(user User) (User, error)
+	DeleteUser(ID string) (User, error)
+	EnsureUser(user User) (User, bool, error)
+}
+
+// OrganizationService operations on organizations
+type OrganizationService interface {
+	GetOrganizations() ([]Org, error)
+	GetOrganizationName(name string) (Org, error)
+	GetOrganizationID(OrgID string) (Org, error)
+	CreateOrganization(org Org) (Org, error)
+	DeleteOrganization(ID string) (Org, error)
+	EnsureOrganization(org Org) (Org, bool, error)
+}
+
+// MembershipService operations on the users of organizations
+type MembershipService interface {
+	GetOrganizationUsers(ID string) ([]UserInOrganization, error)
+	GetOrganizationUserID(ID string, userID string) (UserInOrganization, error)
+	CreateUserOrganization(OrgID string, user UserInOrganization) (UserInOrganization, error)
+	DeleteOrganizationUser(userID string, orgID string) (UserInOrganization, error)
+	GetUserOrganizations(userID string) ([]UserOrganization, error)
+	AddOrganizationUsers(OrgID string, users []UserInOrganization, concurrency int) []BulkResult
+	RemoveOrganizationUsers(orgID string, userIDs []string, concurrency int) []BulkResult
+	CopyOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (UserInOrganization, error)
+	MoveOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (UserInOrganization, error)
+	EnsureOrganizationUser(OrgID string, user UserInOrganization) (UserInOrganization, bool, error)
+}
+
+// Client every operation of the visualization API.
+// VisualizationClient implements it, clientmock.Client mocks it.
+type Client interface {
+	Authenticate() (AuthToken, error)
+	UserService
+	OrganizationService
+	MembershipService
+}
+
+var _ Client = (*VisualizationClient)(nil)
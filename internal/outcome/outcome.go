@@ -0,0 +1,46 @@
+// Package outcome reports what happened to the objects created by the
+// importer and snapshot packages.
+package outcome
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Status outcome of an object
+type Status string
+
+// Outcomes of the objects
+const (
+	Created Status = "created"
+	Skipped Status = "skipped"
+	Failed  Status = "failed"
+)
+
+// Count returns the number of statuses equal to status
+func Count(statuses []Status, status Status) (count int) {
+	for _, s := range statuses {
+		if s == status {
+			count++
+		}
+	}
+	return
+}
+
+// WriteSummary prints the number of created, skipped and failed objects
+func WriteSummary(w io.Writer, statuses []Status) error {
+	_, err := fmt.Fprintf(w, "%d created, %d skipped, %d failed.\n",
+		Count(statuses, Created), Count(statuses, Skipped), Count(statuses, Failed))
+	return err
+}
+
+// RandomPassword returns a password for a created user nobody chose one for
+func RandomPassword() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
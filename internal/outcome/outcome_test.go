@@ -0,0 +1,25 @@
+package outcome
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSummary(t *testing.T) {
+	statuses := []Status{Created, Failed, Created, Skipped}
+	assert.Equal(t, 2, Count(statuses, Created))
+
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, WriteSummary(out, statuses))
+	assert.Equal(t, "2 created, 1 skipped, 1 failed.\n", out.String())
+}
+
+func TestRandomPassword(t *testing.T) {
+	first, err := RandomPassword()
+	assert.Equal(t, nil, err)
+	second, _ := RandomPassword()
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the secrets in the logs
+const Redacted = "[REDACTED]"
+
+// Logger receives the structured records of the requests made by the client.
+// Args are alternating keys and values; *slog.Logger implements Logger.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// logAttempt logs one attempt of a request.
+// Status is zero when no response was received.
+func (v *VisualizationClient) logAttempt(request *http.Request, payload []byte, status int, response []byte, latency time.Duration, retries int, err error) {
+	if v.logger == nil {
+		return
+	}
+
+	args := []interface{}{
+		"method", request.Method,
+		"path", request.URL.Path,
+		"status", status,
+		"latency", latency,
+		"retries", retries,
+		"headers", redactHeader(request.Header),
+	}
+	if len(payload) > 0 {
+		args = append(args, "requestBody", RedactBody(payload))
+	}
+	if len(response) > 0 {
+		args = append(args, "responseBody", RedactBody(response))
+	}
+
+	switch {
+	case err != nil:
+		v.logger.Error("visualization request failed", append(args, "error", err.Error())...)
+	case status >= 500:
+		v.logger.Error("visualization request failed", args...)
+	default:
+		v.logger.Debug("visualization request", args...)
+	}
+}
+
+// secretHeaders headers carrying credentials
+var secretHeaders = []string{"Authorization", "X-Openstack-Auth-Token"}
+
+// secretFields JSON fields carrying credentials, compared case insensitively
+var secretFields = []string{"jwt", "password"}
+
+// RedactHeader returns a copy of header with the credentials replaced by
+// Redacted, keeping the scheme of the Authorization header
+func RedactHeader(header http.Header) http.Header {
+	redacted := http.Header{}
+	for key, values := range header {
+		redacted[key] = append([]string(nil), values...)
+	}
+	for _, key := range secretHeaders {
+		value := redacted.Get(key)
+		switch {
+		case value == "":
+		case key == "Authorization" && strings.HasPrefix(value, "Bearer "):
+			redacted.Set(key, "Bearer "+Redacted)
+		default:
+			redacted.Set(key, Redacted)
+		}
+	}
+	return redacted
+}
+
+// redactHeader flattens the header, hiding the JWT and the OpenStack token
+func redactHeader(header http.Header) map[string]string {
+	flat := map[string]string{}
+	redacted := RedactHeader(header)
+	for key := range redacted {
+		flat[key] = redacted.Get(key)
+	}
+	return flat
+}
+
+// RedactBody hides the non-empty password and jwt fields of a JSON body,
+// other bodies are returned as is
+func RedactBody(body []byte) string {
+	var value interface{}
+	if len(body) == 0 || json.Unmarshal(body, &value) != nil {
+		return string(body)
+	}
+
+	data, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return string(body)
+	}
+	return string(data)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if isSecretField(key) {
+				if s, ok := field.(string); !ok || s != "" {
+					value[key] = Redacted
+				}
+				continue
+			}
+			value[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isSecretField(key string) bool {
+	for _, secret := range secretFields {
+		if strings.EqualFold(key, secret) {
+			return true
+		}
+	}
+	return false
+}
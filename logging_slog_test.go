@@ -0,0 +1,31 @@
+//go:build go1.21
+// +build go1.21
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jwt":"secret-jwt"}`)
+	}))
+	defer ts.Close()
+
+	out := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "secret-token", WithLogger(logger))
+	client.Authenticate()
+
+	assert.Contains(t, out.String(), `"msg":"visualization request"`)
+	assert.Contains(t, out.String(), `"path":"/auth/openstack"`)
+	assert.NotContains(t, out.String(), "secret")
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// captureLogger keeps the records it receives
+type captureLogger struct {
+	records []record
+}
+
+func (l *captureLogger) log(level string, msg string, args []interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[args[i].(string)] = args[i+1]
+	}
+	l.records = append(l.records, record{level: level, msg: msg, fields: fields})
+}
+
+func (l *captureLogger) Debug(msg string, args ...interface{}) {
+	l.log("debug", msg, args)
+}
+
+func (l *captureLogger) Error(msg string, args ...interface{}) {
+	l.log("error", msg, args)
+}
+
+func TestLogging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"secret-jwt","token":{"organizationId":"1"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	logger := &captureLogger{}
+	client, err := NewVisualizationClient(ts.URL, http.Client{}, "secret-token", WithLogger(logger))
+	assert.Equal(t, err, nil, "no error")
+	client.CreateUser(User{Login: "alice", Password: "secret-password"})
+
+	for _, r := range logger.records {
+		dump := fmt.Sprint(r.fields)
+		assert.NotContains(t, dump, "secret", "secrets redacted")
+	}
+
+	last := logger.records[len(logger.records)-1]
+	assert.Equal(t, "debug", last.level)
+	assert.Equal(t, "POST", last.fields["method"])
+	assert.Equal(t, "/admin/users", last.fields["path"])
+	assert.Equal(t, 409, last.fields["status"])
+	assert.Equal(t, 0, last.fields["retries"])
+	assert.Equal(t, "Bearer [REDACTED]", last.fields["headers"].(map[string]string)["Authorization"])
+	assert.True(t, strings.Contains(last.fields["requestBody"].(string), `"password":"[REDACTED]"`))
+	assert.Contains(t, last.fields, "latency")
+
+	auth := logger.records[0]
+	assert.Equal(t, "/auth/openstack", auth.fields["path"])
+	assert.Equal(t, "[REDACTED]", auth.fields["headers"].(map[string]string)["X-Openstack-Auth-Token"])
+	assert.Equal(t, `{"jwt":"[REDACTED]","token":{"organizationId":"1"}}`, auth.fields["responseBody"])
+}
+
+func TestRedactHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer jwt")
+	header.Set("X-OpenStack-Auth-Token", "token")
+	header.Set("Content-Type", "application/json")
+
+	redacted := RedactHeader(header)
+	assert.Equal(t, "Bearer [REDACTED]", redacted.Get("Authorization"))
+	assert.Equal(t, "[REDACTED]", redacted.Get("X-OpenStack-Auth-Token"))
+	assert.Equal(t, "application/json", redacted.Get("Content-Type"))
+	assert.Equal(t, "Bearer jwt", header.Get("Authorization"), "header not modified")
+
+	header.Set("Authorization", "Basic abc")
+	assert.Equal(t, "[REDACTED]", RedactHeader(header).Get("Authorization"))
+	assert.Equal(t, http.Header{}, RedactHeader(http.Header{}), "absent headers not added")
+}
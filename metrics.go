@@ -0,0 +1,25 @@
+package client
+
+import "time"
+
+// Metrics receives measurements of the client calls.
+// Package prommetrics implements it with Prometheus collectors.
+type Metrics interface {
+	// ObserveRequest is called for every HTTP attempt of an operation,
+	// status is zero when no response was received
+	ObserveRequest(operation string, status int, latency time.Duration)
+	// IncRetry is called before an operation is retried
+	IncRetry(operation string)
+	// IncReauthentication is called when the JWT is issued again
+	IncReauthentication()
+	// SetTokenExpiry is called with the expiry of every issued JWT
+	SetTokenExpiry(expiresAt time.Time)
+}
+
+// noopMetrics default Metrics discarding everything
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(operation string, status int, latency time.Duration) {}
+func (noopMetrics) IncRetry(operation string)                                          {}
+func (noopMetrics) IncReauthentication()                                               {}
+func (noopMetrics) SetTokenExpiry(expiresAt time.Time)                                 {}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// RoleMapping gives the role a user gets in the target organization of a
+// move or copy from the role the user has in the source organization
+type RoleMapping func(role string) string
+
+// KeepRole gives the user the same role in the target organization
+func KeepRole(role string) string {
+	return role
+}
+
+// MapRoles returns a RoleMapping replacing the roles found in roles and
+// keeping the others, e.g. MapRoles(map[string]string{RoleAdmin: RoleEditor})
+func MapRoles(roles map[string]string) RoleMapping {
+	return func(role string) string {
+		if mapped, ok := roles[role]; ok {
+			return mapped
+		}
+		return role
+	}
+}
+
+// MoveError reports a move that failed after adding the user to the target
+// organization. The addition is rolled back unless RollbackErr is set, in
+// which case the user is a member of both organizations.
+type MoveError struct {
+	User        UserInOrganization
+	Err         error
+	RollbackErr error
+}
+
+// Error generate a error message.
+func (e MoveError) Error() string {
+	if e.RollbackErr != nil {
+		return fmt.Sprintf("moving %s failed: %v; rollback failed, the user is in both organizations: %v", e.User.Login, e.Err, e.RollbackErr)
+	}
+	return fmt.Sprintf("moving %s failed, rolled back: %v", e.User.Login, e.Err)
+}
+
+// CopyOrganizationUser adds the user with ID userID of the organization
+// fromOrgID to the organization toOrgID, with the role given by mapRole
+// (KeepRole when nil). Copying a user already in the target organization with
+// that role succeeds without changing anything.
+func (v *VisualizationClient) CopyOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (user UserInOrganization, err error) {
+	return v.CopyOrganizationUserContext(context.Background(), userID, fromOrgID, toOrgID, mapRole)
+}
+
+// CopyOrganizationUserContext is CopyOrganizationUser within ctx
+func (v *VisualizationClient) CopyOrganizationUserContext(ctx context.Context, userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (user UserInOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "CopyOrganizationUser",
+		Attribute{Key: AttributeOrgID, Value: fromOrgID}, Attribute{Key: AttributeUserID, Value: userID})
+	defer func() { endSpan(span, err) }()
+
+	user, _, err = v.copyOrganizationUser(ctx, userID, fromOrgID, toOrgID, mapRole)
+	return
+}
+
+// MoveOrganizationUser moves the user with ID userID from the organization
+// fromOrgID to the organization toOrgID, with the role given by mapRole
+// (KeepRole when nil). The user is added to the target organization before
+// being removed from the source one, so access is never lost; when the
+// removal fails the addition is rolled back and a MoveError returned.
+func (v *VisualizationClient) MoveOrganizationUser(userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (user UserInOrganization, err error) {
+	return v.MoveOrganizationUserContext(context.Background(), userID, fromOrgID, toOrgID, mapRole)
+}
+
+// MoveOrganizationUserContext is MoveOrganizationUser within ctx
+func (v *VisualizationClient) MoveOrganizationUserContext(ctx context.Context, userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (user UserInOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "MoveOrganizationUser",
+		Attribute{Key: AttributeOrgID, Value: fromOrgID}, Attribute{Key: AttributeUserID, Value: userID})
+	defer func() { endSpan(span, err) }()
+
+	user, added, err := v.copyOrganizationUser(ctx, userID, fromOrgID, toOrgID, mapRole)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+
+	_, err = v.deleteOrganizationUser(ctx, userID, fromOrgID)
+	if err != nil {
+		moveErr := MoveError{User: user, Err: err}
+		if added {
+			_, moveErr.RollbackErr = v.deleteOrganizationUser(ctx, userID, toOrgID)
+		}
+		return UserInOrganization{}, moveErr
+	}
+	return user, nil
+}
+
+// copyOrganizationUser adds the user to the target organization and reports
+// whether it was added, or was already there with the mapped role
+func (v *VisualizationClient) copyOrganizationUser(ctx context.Context, userID string, fromOrgID string, toOrgID string, mapRole RoleMapping) (user UserInOrganization, added bool, err error) {
+	if fromOrgID == toOrgID {
+		return UserInOrganization{}, false, fmt.Errorf("user %s is already in organization %s", userID, toOrgID)
+	}
+	if mapRole == nil {
+		mapRole = KeepRole
+	}
+
+	source, err := v.organizationUser(ctx, fromOrgID, userID)
+	if err != nil {
+		return
+	}
+	role := mapRole(source.Role)
+	switch role {
+	case RoleViewer, RoleEditor, RoleAdmin:
+	default:
+		return UserInOrganization{}, false, fmt.Errorf("role %q of %s is mapped to invalid role %q", source.Role, source.Login, role)
+	}
+
+	user, err = v.createUserOrganization(ctx, toOrgID, UserInOrganization{OrgID: toOrgID, UserID: userID,
+		Login: source.Login, Email: source.Email, Role: role})
+	if err == nil {
+		return user, true, nil
+	}
+	if !hasCode(err, "409") {
+		return UserInOrganization{}, false, err
+	}
+
+	existing, err := v.organizationUser(ctx, toOrgID, userID)
+	if err != nil {
+		return
+	}
+	if existing.Role != role {
+		return UserInOrganization{}, false, fmt.Errorf("user %s is already in organization %s as %s, not %s", source.Login, toOrgID, existing.Role, role)
+	}
+	return existing, false, nil
+}
+
+// organizationUser returns the membership of a user, a 404 error when the
+// user is not in the organization
+func (v *VisualizationClient) organizationUser(ctx context.Context, orgID string, userID string) (UserInOrganization, error) {
+	users, _, err := v.getOrganizationUsers(ctx, orgID)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	for _, user := range users {
+		if user.UserID == userID {
+			return user, nil
+		}
+	}
+	return UserInOrganization{}, VisualizationError{code: "404", message: "ID not found",
+		description: "Provided ID to Delete/Get was not found"}
+}
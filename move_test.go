@@ -0,0 +1,140 @@
+package client_test
+
+import (
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newReorganization returns a server with the organizations source (ID 1)
+// and target (ID 2), alice (ID 3) Admin of source and bob (ID 4) Viewer of
+// both
+func newReorganization() *visualizationtest.Server {
+	s := visualizationtest.NewServer("token")
+	source := s.AddOrganization("source")
+	target := s.AddOrganization("target")
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com"})
+	bob := s.AddUser(client.User{Login: "bob"})
+	s.AddMember(source.OrganizationID, alice.UserID, client.RoleAdmin)
+	s.AddMember(source.OrganizationID, bob.UserID, client.RoleViewer)
+	s.AddMember(target.OrganizationID, bob.UserID, client.RoleViewer)
+	return s
+}
+
+// roles returns "login:role" of the members of an organization
+func roles(s *visualizationtest.Server, orgID string) (members []string) {
+	for _, member := range s.Members(orgID) {
+		members = append(members, member.Login+":"+member.Role)
+	}
+	return
+}
+
+func TestMoveOrganizationUser(t *testing.T) {
+	tests := []struct {
+		description    string
+		userID         string
+		mapRole        client.RoleMapping
+		expectedErr    string
+		expectedSource []string
+		expectedTarget []string
+	}{
+		{
+			description:    "role kept",
+			userID:         "3",
+			expectedSource: []string{"bob:Viewer"},
+			expectedTarget: []string{"alice:Admin", "bob:Viewer"},
+		},
+		{
+			description:    "role mapped",
+			userID:         "3",
+			mapRole:        client.MapRoles(map[string]string{client.RoleAdmin: client.RoleEditor}),
+			expectedSource: []string{"bob:Viewer"},
+			expectedTarget: []string{"alice:Editor", "bob:Viewer"},
+		},
+		{
+			description:    "already in the target with the role",
+			userID:         "4",
+			expectedSource: []string{"alice:Admin"},
+			expectedTarget: []string{"bob:Viewer"},
+		},
+		{
+			description:    "already in the target with another role",
+			userID:         "4",
+			mapRole:        client.MapRoles(map[string]string{client.RoleViewer: client.RoleEditor}),
+			expectedErr:    "user bob is already in organization 2 as Viewer, not Editor",
+			expectedSource: []string{"alice:Admin", "bob:Viewer"},
+			expectedTarget: []string{"bob:Viewer"},
+		},
+		{
+			description:    "invalid mapped role",
+			userID:         "3",
+			mapRole:        func(string) string { return "Owner" },
+			expectedErr:    `role "Admin" of alice is mapped to invalid role "Owner"`,
+			expectedSource: []string{"alice:Admin", "bob:Viewer"},
+			expectedTarget: []string{"bob:Viewer"},
+		},
+		{
+			description:    "not in the source",
+			userID:         "5",
+			expectedErr:    "ERROR: Provided ID to Delete/Get was not found",
+			expectedSource: []string{"alice:Admin", "bob:Viewer"},
+			expectedTarget: []string{"bob:Viewer"},
+		},
+	}
+	for _, testCase := range tests {
+		s := newReorganization()
+		user, err := s.Client().MoveOrganizationUser(testCase.userID, "1", "2", testCase.mapRole)
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+			assert.Equal(t, testCase.userID, user.UserID, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+		}
+		assert.Equal(t, testCase.expectedSource, roles(s, "1"), testCase.description)
+		assert.Equal(t, testCase.expectedTarget, roles(s, "2"), testCase.description)
+		s.Close()
+	}
+}
+
+func TestMoveOrganizationUserRollback(t *testing.T) {
+	s := newReorganization()
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Method: "DELETE", Path: "/admin/organizations/1/users/3", Status: http.StatusInternalServerError})
+
+	_, err := s.Client().MoveOrganizationUser("3", "1", "2", nil)
+	moveErr, ok := err.(client.MoveError)
+	assert.True(t, ok)
+	assert.Equal(t, nil, moveErr.RollbackErr)
+	assert.Contains(t, err.Error(), "moving alice failed, rolled back")
+	assert.Equal(t, []string{"alice:Admin", "bob:Viewer"}, roles(s, "1"))
+	assert.Equal(t, []string{"bob:Viewer"}, roles(s, "2"), "addition rolled back")
+
+	s.InjectFault(visualizationtest.Fault{Method: "DELETE", Path: "/admin/organizations/2/users/3", Status: http.StatusInternalServerError})
+	_, err = s.Client().MoveOrganizationUser("3", "1", "2", nil)
+	moveErr, ok = err.(client.MoveError)
+	assert.True(t, ok)
+	assert.NotEqual(t, nil, moveErr.RollbackErr)
+	assert.Equal(t, []string{"alice:Admin", "bob:Viewer"}, roles(s, "1"))
+	assert.Equal(t, []string{"alice:Admin", "bob:Viewer"}, roles(s, "2"), "access never lost")
+}
+
+func TestCopyOrganizationUser(t *testing.T) {
+	s := newReorganization()
+	defer s.Close()
+	api := s.Client()
+
+	user, err := api.CopyOrganizationUser("3", "1", "2", client.MapRoles(map[string]string{client.RoleAdmin: client.RoleViewer}))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, client.UserInOrganization{OrgID: "2", UserID: "3", Login: "alice", Email: "alice@example.com", Role: client.RoleViewer}, user)
+	assert.Equal(t, []string{"alice:Admin", "bob:Viewer"}, roles(s, "1"))
+	assert.Equal(t, []string{"alice:Viewer", "bob:Viewer"}, roles(s, "2"))
+
+	_, err = api.CopyOrganizationUser("3", "1", "2", client.KeepRole)
+	assert.Equal(t, "user alice is already in organization 2 as Viewer, not Admin", err.Error())
+
+	_, err = api.CopyOrganizationUser("3", "1", "1", nil)
+	assert.Equal(t, "user 3 is already in organization 1", err.Error())
+}
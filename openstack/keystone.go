@@ -0,0 +1,146 @@
+// Package openstack synchronizes Keystone projects and role assignments to
+// visualization organizations and memberships.
+package openstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// KeystoneError errors for Keystone client
+type KeystoneError struct {
+	URL        string
+	StatusCode int
+}
+
+// Error generate a error message.
+func (e KeystoneError) Error() string {
+	return fmt.Sprintf("ERROR: keystone request %s failed with status %d", e.URL, e.StatusCode)
+}
+
+// Keystone client for the Keystone v3 identity API
+type Keystone struct {
+	url    string
+	client *http.Client
+	token  string
+}
+
+// NewKeystone returns client authenticated with the given token
+func NewKeystone(url string, client http.Client, token string) *Keystone {
+	return &Keystone{url: url, client: &client, token: token}
+}
+
+// Project Keystone project
+type Project struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	DomainID string `json:"domain_id"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// KeystoneUser Keystone user
+type KeystoneUser struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NamedRef reference to a Keystone entity returned with include_names
+type NamedRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RoleAssignment role a user has on a project
+type RoleAssignment struct {
+	Role  NamedRef `json:"role"`
+	User  NamedRef `json:"user"`
+	Scope struct {
+		Project NamedRef `json:"project"`
+	} `json:"scope"`
+}
+
+type links struct {
+	Next string `json:"next"`
+}
+
+// get follows the pagination links of a collection, calling decode for every page
+func (k *Keystone) get(reqURL string, decode func(page *json.Decoder) (links, error)) error {
+	for reqURL != "" {
+		request, err := http.NewRequest("GET", reqURL, nil)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("X-Auth-Token", k.token)
+		request.Header.Set("Accept", "application/json")
+
+		response, err := k.client.Do(request)
+		if err != nil {
+			return err
+		}
+		if response.StatusCode != 200 {
+			response.Body.Close()
+			return KeystoneError{URL: reqURL, StatusCode: response.StatusCode}
+		}
+
+		next, err := decode(json.NewDecoder(response.Body))
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+		reqURL = next.Next
+	}
+	return nil
+}
+
+// GetProjects returns list of projects, restricted to domainID when not empty
+func (k *Keystone) GetProjects(domainID string) (projects []Project, err error) {
+	reqURL := k.url + "/v3/projects"
+	if domainID != "" {
+		reqURL += "?domain_id=" + url.QueryEscape(domainID)
+	}
+
+	err = k.get(reqURL, func(page *json.Decoder) (links, error) {
+		var body struct {
+			Projects []Project `json:"projects"`
+			Links    links     `json:"links"`
+		}
+		err := page.Decode(&body)
+		projects = append(projects, body.Projects...)
+		return body.Links, err
+	})
+	return
+}
+
+// GetUsers returns list of users
+func (k *Keystone) GetUsers() (users []KeystoneUser, err error) {
+	err = k.get(k.url+"/v3/users", func(page *json.Decoder) (links, error) {
+		var body struct {
+			Users []KeystoneUser `json:"users"`
+			Links links          `json:"links"`
+		}
+		err := page.Decode(&body)
+		users = append(users, body.Users...)
+		return body.Links, err
+	})
+	return
+}
+
+// GetRoleAssignments returns the effective project role assignments of users.
+// Group assignments are expanded to their members by Keystone.
+func (k *Keystone) GetRoleAssignments() (assignments []RoleAssignment, err error) {
+	reqURL := k.url + "/v3/role_assignments?effective&include_names=true"
+	err = k.get(reqURL, func(page *json.Decoder) (links, error) {
+		var body struct {
+			RoleAssignments []RoleAssignment `json:"role_assignments"`
+			Links           links            `json:"links"`
+		}
+		err := page.Decode(&body)
+		assignments = append(assignments, body.RoleAssignments...)
+		return body.Links, err
+	})
+	return
+}
@@ -0,0 +1,83 @@
+package openstack
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newKeystoneServer stands in for Keystone, serving projects in two pages
+func newKeystoneServer(token string) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/v3/projects" && r.URL.Query().Get("page") == "":
+			fmt.Fprintf(w, `{"projects":[
+				{"id":"p1","name":"alpha","domain_id":"default","enabled":true},
+				{"id":"p2","name":"beta","domain_id":"default","enabled":false}],
+				"links":{"next":"%s/v3/projects?page=2"}}`, ts.URL)
+		case r.URL.Path == "/v3/projects":
+			fmt.Fprint(w, `{"projects":[{"id":"p3","name":"gamma","domain_id":"default","enabled":true}],"links":{"next":null}}`)
+		case r.URL.Path == "/v3/users":
+			fmt.Fprint(w, `{"users":[
+				{"id":"u1","name":"alice","email":"alice@example.com","enabled":true},
+				{"id":"u2","name":"bob","email":"bob@example.com","enabled":true},
+				{"id":"u3","name":"mallory","enabled":false}],"links":{}}`)
+		case r.URL.Path == "/v3/role_assignments":
+			fmt.Fprint(w, `{"role_assignments":[
+				{"role":{"id":"r1","name":"reader"},"user":{"id":"u1","name":"alice"},"scope":{"project":{"id":"p1","name":"alpha"}}},
+				{"role":{"id":"r2","name":"admin"},"user":{"id":"u1","name":"alice"},"scope":{"project":{"id":"p1","name":"alpha"}}},
+				{"role":{"id":"r3","name":"member"},"user":{"id":"u2","name":"bob"},"scope":{"project":{"id":"p1","name":"alpha"}}},
+				{"role":{"id":"r4","name":"heat_stack_owner"},"user":{"id":"u2","name":"bob"},"scope":{"project":{"id":"p3","name":"gamma"}}},
+				{"role":{"id":"r2","name":"admin"},"user":{"id":"u3","name":"mallory"},"scope":{"project":{"id":"p3","name":"gamma"}}}],
+				"links":{}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return ts
+}
+
+func TestGetProjects(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	keystone := NewKeystone(ts.URL, http.Client{}, "token")
+	projects, err := keystone.GetProjects("")
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, []Project{
+		{ID: "p1", Name: "alpha", DomainID: "default", Enabled: true},
+		{ID: "p2", Name: "beta", DomainID: "default", Enabled: false},
+		{ID: "p3", Name: "gamma", DomainID: "default", Enabled: true},
+	}, projects, "all pages read")
+}
+
+func TestGetRoleAssignments(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	keystone := NewKeystone(ts.URL, http.Client{}, "token")
+	assignments, err := keystone.GetRoleAssignments()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, 5, len(assignments))
+	assert.Equal(t, "admin", assignments[1].Role.Name)
+	assert.Equal(t, "alice", assignments[1].User.Name)
+	assert.Equal(t, "p1", assignments[1].Scope.Project.ID)
+}
+
+func TestKeystoneUnauthorized(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	keystone := NewKeystone(ts.URL, http.Client{}, "wrong")
+	_, err := keystone.GetUsers()
+	assert.Equal(t, KeystoneError{URL: ts.URL + "/v3/users", StatusCode: 401}, err)
+}
@@ -0,0 +1,253 @@
+package openstack
+
+import (
+	"io"
+	"sort"
+	"strings"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/archive"
+	"github.com/kbhonagiri16/visualization-client/reconcile"
+)
+
+// Source provides the projects, users and role assignments to synchronize
+type Source interface {
+	GetProjects(domainID string) ([]Project, error)
+	GetUsers() ([]KeystoneUser, error)
+	GetRoleAssignments() ([]RoleAssignment, error)
+}
+
+// DefaultRoleMapping maps the usual Keystone roles to organization roles
+var DefaultRoleMapping = map[string]string{
+	"admin":    client.RoleAdmin,
+	"member":   client.RoleEditor,
+	"_member_": client.RoleViewer,
+	"reader":   client.RoleViewer,
+}
+
+// roleRank orders organization roles so the most privileged mapping wins
+var roleRank = map[string]int{
+	client.RoleViewer: 1,
+	client.RoleEditor: 2,
+	client.RoleAdmin:  3,
+}
+
+// Config describes how projects are mapped to organizations
+type Config struct {
+	// DomainID restricts the synchronization to the projects of a domain
+	DomainID string
+	// OrgPrefix is prepended to project names to build organization names.
+	// Only organizations carrying the prefix are archived when their
+	// project is disabled or deleted, so archiving requires a prefix.
+	OrgPrefix string
+	// RoleMapping maps Keystone role names to organization roles,
+	// DefaultRoleMapping when nil. Unmapped roles grant no membership.
+	RoleMapping map[string]string
+	// PreserveLogins are never removed from organizations
+	PreserveLogins []string
+	// Archiver archives the organizations of disabled or deleted projects,
+	// keeping their admins, and unarchives them when their project is enabled
+	// again. When nil those organizations are emptied instead.
+	// Archiving is skipped in dry-run mode.
+	Archiver *archive.Archiver
+	// DryRun only prints the plan to Out without applying it
+	DryRun bool
+	Out    io.Writer
+	Format reconcile.Format
+}
+
+// Syncer creates organizations for projects and keeps their memberships in
+// line with the Keystone role assignments
+type Syncer struct {
+	source Source
+	api    reconcile.API
+	config Config
+}
+
+// NewSyncer returns a syncer from source to api
+func NewSyncer(source Source, api reconcile.API, config Config) *Syncer {
+	if config.RoleMapping == nil {
+		config.RoleMapping = DefaultRoleMapping
+	}
+	return &Syncer{source: source, api: api, config: config}
+}
+
+// Login returns the login of a Keystone user: its name when it is a valid
+// login, otherwise its email, otherwise its ID. Keystone names may contain
+// characters, such as spaces, that logins do not allow.
+func Login(user KeystoneUser) string {
+	if (client.User{Login: user.Name}).Validate() == nil {
+		return user.Name
+	}
+	if user.Email != "" && (client.User{Login: user.Email}).Validate() == nil {
+		return user.Email
+	}
+	return user.ID
+}
+
+// OrganizationName returns the organization name of a project
+func (s *Syncer) OrganizationName(project Project) string {
+	return s.config.OrgPrefix + project.Name
+}
+
+// Tenants returns the organization names of the enabled projects,
+// which makes the syncer usable as a gc.TenantSource
+func (s *Syncer) Tenants() (names []string, err error) {
+	projects, err := s.source.GetProjects(s.config.DomainID)
+	if err != nil {
+		return
+	}
+
+	for _, project := range projects {
+		if project.Enabled {
+			names = append(names, s.OrganizationName(project))
+		}
+	}
+	return
+}
+
+// DesiredState builds the desired state from Keystone.
+// Without Archiver, managed organizations whose project disappeared or got
+// disabled are kept without members, which archives them.
+func (s *Syncer) DesiredState() (state reconcile.State, err error) {
+	projects, err := s.source.GetProjects(s.config.DomainID)
+	if err != nil {
+		return
+	}
+	users, err := s.source.GetUsers()
+	if err != nil {
+		return
+	}
+	assignments, err := s.source.GetRoleAssignments()
+	if err != nil {
+		return
+	}
+
+	keystoneUsers := map[string]KeystoneUser{}
+	for _, user := range users {
+		keystoneUsers[user.ID] = user
+	}
+
+	// project ID -> user ID -> role
+	roles := map[string]map[string]string{}
+	for _, assignment := range assignments {
+		role, ok := s.config.RoleMapping[assignment.Role.Name]
+		if !ok {
+			continue
+		}
+		user, ok := keystoneUsers[assignment.User.ID]
+		if !ok || !user.Enabled {
+			continue
+		}
+
+		projectID := assignment.Scope.Project.ID
+		if roles[projectID] == nil {
+			roles[projectID] = map[string]string{}
+		}
+		if roleRank[role] > roleRank[roles[projectID][user.ID]] {
+			roles[projectID][user.ID] = role
+		}
+	}
+
+	active := map[string]bool{}
+	for _, project := range projects {
+		if !project.Enabled {
+			continue
+		}
+		org := reconcile.Organization{Name: s.OrganizationName(project)}
+		active[org.Name] = true
+
+		var userIDs []string
+		for userID := range roles[project.ID] {
+			userIDs = append(userIDs, userID)
+		}
+		sort.Strings(userIDs)
+		for _, userID := range userIDs {
+			user := keystoneUsers[userID]
+			org.Users = append(org.Users, reconcile.Member{Login: Login(user), Email: user.Email,
+				Name: user.Name, Role: roles[project.ID][userID]})
+		}
+		state.Organizations = append(state.Organizations, org)
+	}
+
+	if s.config.OrgPrefix == "" || s.config.Archiver != nil {
+		return
+	}
+
+	orgs, err := s.api.GetOrganizations()
+	if err != nil {
+		return reconcile.State{}, err
+	}
+	for _, org := range orgs {
+		if strings.HasPrefix(org.Name, s.config.OrgPrefix) && !active[org.Name] {
+			state.Organizations = append(state.Organizations, reconcile.Organization{Name: org.Name})
+		}
+	}
+	return
+}
+
+// Sync reconciles the organizations with Keystone and returns the plan
+func (s *Syncer) Sync() (plan reconcile.Plan, err error) {
+	state, err := s.DesiredState()
+	if err != nil {
+		return
+	}
+
+	if s.config.Archiver != nil && !s.config.DryRun {
+		err = s.syncArchives(state)
+		if err != nil {
+			return
+		}
+	}
+
+	reconciler := reconcile.New(s.api, reconcile.Options{
+		PreserveLogins: s.config.PreserveLogins,
+		DryRun:         s.config.DryRun,
+		Out:            s.config.Out,
+		Format:         s.config.Format,
+	})
+	return reconciler.Reconcile(state)
+}
+
+// syncArchives archives the managed organizations absent from the desired
+// state and unarchives the archived ones back in it, before reconciling
+// their members
+func (s *Syncer) syncArchives(state reconcile.State) error {
+	if s.config.OrgPrefix == "" {
+		return nil
+	}
+
+	active := map[string]bool{}
+	for _, org := range state.Organizations {
+		active[org.Name] = true
+	}
+
+	orgs, err := s.api.GetOrganizations()
+	if err != nil {
+		return err
+	}
+	for _, org := range orgs {
+		if !strings.HasPrefix(org.Name, s.config.OrgPrefix) {
+			continue
+		}
+		if !active[org.Name] {
+			_, err = s.config.Archiver.Archive(org.OrganizationID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		archived, err := s.config.Archiver.Archived(org.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if archived {
+			_, err = s.config.Archiver.Unarchive(org.OrganizationID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
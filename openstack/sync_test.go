@@ -0,0 +1,237 @@
+package openstack
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/archive"
+	"github.com/kbhonagiri16/visualization-client/reconcile"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI keeps the visualization state in memory
+type fakeAPI struct {
+	orgs    []client.Org
+	users   []client.User
+	members map[string][]client.UserInOrganization
+}
+
+func (f *fakeAPI) GetOrganizations() ([]client.Org, error) {
+	return f.orgs, nil
+}
+
+func (f *fakeAPI) GetUsers() ([]client.User, error) {
+	return f.users, nil
+}
+
+func (f *fakeAPI) GetOrganizationUsers(ID string) ([]client.UserInOrganization, error) {
+	return f.members[ID], nil
+}
+
+func (f *fakeAPI) CreateOrganization(org client.Org) (client.Org, error) {
+	org.OrganizationID = fmt.Sprint(len(f.orgs) + 1)
+	f.orgs = append(f.orgs, org)
+	return org, nil
+}
+
+func (f *fakeAPI) CreateUser(user client.User) (client.User, error) {
+	user.UserID = fmt.Sprint(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeAPI) CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error) {
+	for _, known := range f.users {
+		if known.Login == user.Login {
+			user.UserID = known.UserID
+		}
+	}
+	f.members[OrgID] = append(f.members[OrgID], user)
+	return user, nil
+}
+
+func (f *fakeAPI) DeleteOrganizationUser(userID string, orgID string) (client.UserInOrganization, error) {
+	var kept []client.UserInOrganization
+	for _, user := range f.members[orgID] {
+		if user.UserID != userID {
+			kept = append(kept, user)
+		}
+	}
+	f.members[orgID] = kept
+	return client.UserInOrganization{}, nil
+}
+
+func (f *fakeAPI) DeleteOrganization(ID string) (client.Org, error) {
+	return client.Org{}, nil
+}
+
+func (f *fakeAPI) GetOrganizationID(OrgID string) (client.Org, error) {
+	for _, org := range f.orgs {
+		if org.OrganizationID == OrgID {
+			return org, nil
+		}
+	}
+	return client.Org{}, fmt.Errorf("organization %s not found", OrgID)
+}
+
+func TestDesiredState(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	syncer := NewSyncer(NewKeystone(ts.URL, http.Client{}, "token"), &fakeAPI{}, Config{})
+	state, err := syncer.DesiredState()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, reconcile.State{Organizations: []reconcile.Organization{
+		{Name: "alpha", Users: []reconcile.Member{
+			{Login: "alice", Email: "alice@example.com", Name: "alice", Role: "Admin"},
+			{Login: "bob", Email: "bob@example.com", Name: "bob", Role: "Editor"},
+		}},
+		{Name: "gamma"},
+	}}, state, "highest mapped role wins, disabled users and projects skipped")
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		description   string
+		user          KeystoneUser
+		expectedLogin string
+	}{
+		{"valid name", KeystoneUser{ID: "u1", Name: "alice", Email: "alice@example.com"}, "alice"},
+		{"name with a space", KeystoneUser{ID: "u1", Name: "Alice Smith", Email: "alice@example.com"}, "alice@example.com"},
+		{"name with a space, no email", KeystoneUser{ID: "u1", Name: "Alice Smith"}, "u1"},
+	}
+	for _, testCase := range tests {
+		assert.Equal(t, testCase.expectedLogin, Login(testCase.user), testCase.description)
+	}
+}
+
+// stubSource serves fixed Keystone data
+type stubSource struct {
+	projects    []Project
+	users       []KeystoneUser
+	assignments []RoleAssignment
+}
+
+func (s stubSource) GetProjects(domainID string) ([]Project, error) { return s.projects, nil }
+func (s stubSource) GetUsers() ([]KeystoneUser, error)              { return s.users, nil }
+func (s stubSource) GetRoleAssignments() ([]RoleAssignment, error)  { return s.assignments, nil }
+
+func TestSyncNameWithSpace(t *testing.T) {
+	var assignment RoleAssignment
+	assignment.Role.Name = "admin"
+	assignment.User.ID = "u1"
+	assignment.Scope.Project.ID = "p1"
+	source := stubSource{
+		projects:    []Project{{ID: "p1", Name: "alpha", Enabled: true}},
+		users:       []KeystoneUser{{ID: "u1", Name: "Alice Smith", Email: "alice@example.com", Enabled: true}},
+		assignments: []RoleAssignment{assignment},
+	}
+	api := &fakeAPI{members: map[string][]client.UserInOrganization{}}
+
+	_, err := NewSyncer(source, api, Config{}).Sync()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(api.users))
+	assert.Equal(t, "alice@example.com", api.users[0].Login)
+	assert.Equal(t, "Alice Smith", api.users[0].Name)
+	assert.Equal(t, nil, api.users[0].Validate(), "accepted by the API")
+}
+
+func TestSync(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	api := &fakeAPI{
+		orgs: []client.Org{{OrganizationID: "1", Name: "os-beta"}, {OrganizationID: "2", Name: "Main Org."}},
+		users: []client.User{
+			{UserID: "1", Login: "admin"},
+			{UserID: "2", Login: "carol"},
+		},
+		members: map[string][]client.UserInOrganization{
+			"1": {{OrgID: "1", UserID: "1", Login: "admin", Role: "Admin"}, {OrgID: "1", UserID: "2", Login: "carol", Role: "Viewer"}},
+		},
+	}
+	config := Config{
+		OrgPrefix:      "os-",
+		RoleMapping:    map[string]string{"admin": "Admin", "heat_stack_owner": "Viewer"},
+		PreserveLogins: []string{"admin"},
+	}
+
+	plan, err := NewSyncer(NewKeystone(ts.URL, http.Client{}, "token"), api, config).Sync()
+	assert.Equal(t, err, nil, "no error")
+
+	var lines []string
+	for _, op := range plan.Operations {
+		lines = append(lines, op.String())
+	}
+	assert.Equal(t, []string{
+		"create-organization os-alpha",
+		"create-user alice",
+		"create-organization os-gamma",
+		"create-user bob",
+		"add-member os-alpha/alice Admin",
+		"add-member os-gamma/bob Viewer",
+		"remove-member os-beta/carol",
+	}, lines, "disabled project archived, unprefixed org untouched")
+
+	assert.Equal(t, []client.UserInOrganization{{OrgID: "1", UserID: "1", Login: "admin", Role: "Admin"}}, api.members["1"])
+	assert.Equal(t, "Admin", api.members["3"][0].Role)
+}
+
+func TestSyncArchive(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	api := &fakeAPI{
+		orgs: []client.Org{{OrganizationID: "1", Name: "os-beta"}, {OrganizationID: "2", Name: "os-alpha"}},
+		users: []client.User{
+			{UserID: "1", Login: "admin"},
+			{UserID: "2", Login: "carol"},
+		},
+		members: map[string][]client.UserInOrganization{
+			"1": {{OrgID: "1", UserID: "1", Login: "admin", Role: "Admin"}, {OrgID: "1", UserID: "2", Login: "carol", Role: "Viewer"}},
+		},
+	}
+	store := archive.NewMemoryStore()
+	store.Save(archive.Record{OrgID: "2", Organization: "os-alpha",
+		Members: []client.UserInOrganization{{OrgID: "2", UserID: "2", Login: "carol", Role: "Editor"}}})
+	config := Config{
+		OrgPrefix:   "os-",
+		RoleMapping: map[string]string{"admin": "Admin", "heat_stack_owner": "Viewer"},
+		Archiver:    archive.New(api, store, archive.Options{}),
+	}
+
+	plan, err := NewSyncer(NewKeystone(ts.URL, http.Client{}, "token"), api, config).Sync()
+	assert.Equal(t, err, nil, "no error")
+
+	var lines []string
+	for _, op := range plan.Operations {
+		lines = append(lines, op.String())
+	}
+	assert.Equal(t, []string{
+		"create-user alice",
+		"create-organization os-gamma",
+		"create-user bob",
+		"add-member os-alpha/alice Admin",
+		"add-member os-gamma/bob Viewer",
+		"remove-member os-alpha/carol",
+	}, lines, "archived organization restored before reconciling it")
+
+	assert.Equal(t, []client.UserInOrganization{{OrgID: "1", UserID: "1", Login: "admin", Role: "Admin"}}, api.members["1"])
+	record, err := store.Load("1")
+	assert.Equal(t, err, nil, "disabled project archived")
+	assert.Equal(t, []client.UserInOrganization{{OrgID: "1", UserID: "2", Login: "carol", Role: "Viewer"}}, record.Members)
+	_, err = store.Load("2")
+	assert.Equal(t, archive.ErrNotArchived, err, "enabled project unarchived")
+}
+
+func TestTenants(t *testing.T) {
+	ts := newKeystoneServer("token")
+	defer ts.Close()
+
+	syncer := NewSyncer(NewKeystone(ts.URL, http.Client{}, "token"), &fakeAPI{}, Config{OrgPrefix: "os-"})
+	names, err := syncer.Tenants()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, []string{"os-alpha", "os-gamma"}, names)
+}
@@ -0,0 +1,78 @@
+package client
+
+import "time"
+
+// Option configures a VisualizationClient
+type Option func(*VisualizationClient)
+
+// WithLogger logs every request and response to logger, secrets redacted
+func WithLogger(logger Logger) Option {
+	return func(v *VisualizationClient) {
+		v.logger = logger
+	}
+}
+
+// WithRetries retries a failed request up to n times, waiting as configured
+// by WithBackoff in between.
+// Requests are retried on 401 answers after re-authenticating. GET and
+// DELETE requests are also retried on network errors and 5xx answers, POST
+// requests only with WithNonIdempotentRetries.
+func WithRetries(n int) Option {
+	return func(v *VisualizationClient) {
+		v.retry.retries = n
+	}
+}
+
+// WithBackoff waits about backoff before the first retry, doubling the wait
+// for every following retry up to maxBackoff. Half of each wait is random.
+// DefaultBackoff and DefaultMaxBackoff are used otherwise.
+func WithBackoff(backoff time.Duration, maxBackoff time.Duration) Option {
+	return func(v *VisualizationClient) {
+		v.retry.backoff = backoff
+		v.retry.maxBackoff = maxBackoff
+	}
+}
+
+// WithNonIdempotentRetries also retries POST requests on network errors and
+// 5xx answers. A create may have been processed before failing: its retry
+// then fails with a 409 error.
+func WithNonIdempotentRetries() Option {
+	return func(v *VisualizationClient) {
+		v.retry.nonIdempotent = true
+	}
+}
+
+// WithMetrics reports the measurements of the client calls to metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(v *VisualizationClient) {
+		if metrics != nil {
+			v.metrics = metrics
+		}
+	}
+}
+
+// WithTracer opens a span for every client call and propagates its trace
+// context in the outgoing requests. The spans of the methods taking a
+// context, such as GetUsersContext, are children of the span in it.
+func WithTracer(tracer Tracer) Option {
+	return func(v *VisualizationClient) {
+		if tracer != nil {
+			v.tracer = tracer
+		}
+	}
+}
+
+// WithConditionalRequests remembers the ETag and Last-Modified validators of
+// the GET responses of GetUsersIfModified, GetOrganizationsIfModified and
+// GetOrganizationUsersIfModified. Their next call is sent with If-None-Match
+// and If-Modified-Since, and a 304 answer returns the remembered result.
+// DeleteUser and DeleteOrganization send If-Match with the ETag of the
+// previous GetUserID or GetOrganizationID, failing with a 412 error when the
+// resource was modified by someone else in between. DeleteOrganizationUser
+// sends none: memberships are only read as lists, so no ETag of a single
+// membership is known.
+func WithConditionalRequests() Option {
+	return func(v *VisualizationClient) {
+		v.validators = newValidators()
+	}
+}
@@ -0,0 +1,97 @@
+// Package oteltrace traces the calls of a VisualizationClient with
+// OpenTelemetry.
+//
+// It lives in its own package so users of the client who do not opt in do
+// not depend on the OpenTelemetry libraries:
+//
+//	tracer := oteltrace.New(otel.GetTracerProvider())
+//	api, err := client.NewVisualizationClient(url, http.Client{}, token, client.WithTracer(tracer))
+//
+// Every operation opens a span with child spans for the authentication and
+// for each HTTP attempt. The trace context is sent in the W3C traceparent
+// and tracestate headers.
+package oteltrace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of the client
+const InstrumentationName = "github.com/kbhonagiri16/visualization-client"
+
+// Tracer implements client.Tracer
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ client.Tracer = (*Tracer)(nil)
+
+// New returns a tracer opening its spans with provider
+func New(provider trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer:     provider.Tracer(InstrumentationName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+// Start implements client.Tracer
+func (t *Tracer) Start(ctx context.Context, name string, attributes ...client.Attribute) (context.Context, client.Span) {
+	kind := trace.SpanKindInternal
+	for _, a := range attributes {
+		if a.Key == client.AttributeMethod {
+			kind = trace.SpanKindClient
+		}
+	}
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(convert(attributes)...))
+	return ctx, otelSpan{span}
+}
+
+// Inject implements client.Tracer
+func (t *Tracer) Inject(ctx context.Context, header http.Header) {
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// otelSpan adapts an OpenTelemetry span to client.Span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttributes(attributes ...client.Attribute) {
+	s.span.SetAttributes(convert(attributes)...)
+}
+
+func (s otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s otelSpan) End() {
+	s.span.End()
+}
+
+// convert returns the OpenTelemetry attributes of attributes
+func convert(attributes []client.Attribute) []attribute.KeyValue {
+	converted := make([]attribute.KeyValue, 0, len(attributes))
+	for _, a := range attributes {
+		switch value := a.Value.(type) {
+		case string:
+			converted = append(converted, attribute.String(a.Key, value))
+		case int:
+			converted = append(converted, attribute.Int(a.Key, value))
+		case bool:
+			converted = append(converted, attribute.Bool(a.Key, value))
+		default:
+			converted = append(converted, attribute.String(a.Key, fmt.Sprint(value)))
+		}
+	}
+	return converted
+}
@@ -0,0 +1,67 @@
+package oteltrace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+func TestTracer(t *testing.T) {
+	var traceparents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	api, _ := client.NewVisualizationClient(ts.URL, http.Client{}, "token", client.WithTracer(New(provider)))
+	ctx, caller := provider.Tracer("caller").Start(context.Background(), "caller")
+	_, err := api.GetOrganizationIDContext(ctx, "4")
+	caller.End()
+	assert.NotEqual(t, nil, err)
+
+	spans := recorder.Ended()
+	names := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		names[span.Name()] = span
+	}
+	assert.Equal(t, 5, len(spans))
+
+	root := names["GetOrganizationID"]
+	assert.Equal(t, InstrumentationName, root.InstrumentationScope().Name)
+	assert.Equal(t, caller.SpanContext().SpanID(), root.Parent().SpanID(), "child of the span of the caller")
+	assert.Equal(t, caller.SpanContext().TraceID(), root.SpanContext().TraceID())
+	assert.Contains(t, root.Attributes(), attribute.String(client.AttributeOrgID, "4"))
+	assert.Equal(t, codes.Error, root.Status().Code)
+
+	authenticate := names["Authenticate"]
+	assert.Equal(t, root.SpanContext().SpanID(), authenticate.Parent().SpanID())
+
+	attempt := names["HTTP GET"]
+	assert.Equal(t, root.SpanContext().SpanID(), attempt.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindClient, attempt.SpanKind())
+	assert.Contains(t, attempt.Attributes(), attribute.Int(client.AttributeStatusCode, 404))
+	assert.Contains(t, attempt.Attributes(), attribute.String(client.AttributeMethod, "GET"))
+
+	traceID := root.SpanContext().TraceID().String()
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", traceID, names["HTTP POST"].SpanContext().SpanID()), traceparents[0])
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", traceID, attempt.SpanContext().SpanID()), traceparents[1])
+}
@@ -0,0 +1,114 @@
+// Package prommetrics exposes the measurements of a VisualizationClient as
+// Prometheus metrics.
+//
+// It lives in its own package so users of the client who do not opt in do
+// not depend on the Prometheus libraries:
+//
+//	metrics := prommetrics.New("visualization")
+//	prometheus.MustRegister(metrics)
+//	api, err := client.NewVisualizationClient(url, http.Client{}, token, client.WithMetrics(metrics))
+package prommetrics
+
+import (
+	"strconv"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector implements client.Metrics and prometheus.Collector
+type Collector struct {
+	requests          *prometheus.CounterVec
+	latency           *prometheus.HistogramVec
+	retries           *prometheus.CounterVec
+	reauthentications prometheus.Counter
+	tokenExpiry       prometheus.Gauge
+}
+
+var _ client.Metrics = (*Collector)(nil)
+var _ prometheus.Collector = (*Collector)(nil)
+
+// New returns a collector whose metrics are prefixed by namespace
+func New(namespace string) *Collector {
+	return &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "requests_total",
+			Help:      "HTTP requests made by the visualization client, by operation and status code.",
+		}, []string{"operation", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the HTTP requests made by the visualization client.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "retries_total",
+			Help:      "Retried operations of the visualization client.",
+		}, []string{"operation"}),
+		reauthentications: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "reauthentications_total",
+			Help:      "JWTs issued to the visualization client.",
+		}),
+		tokenExpiry: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "client",
+			Name:      "token_expiry_timestamp_seconds",
+			Help:      "Expiry of the current JWT of the visualization client.",
+		}),
+	}
+}
+
+// code label of a status, "error" when no response was received
+func code(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
+
+// ObserveRequest implements client.Metrics
+func (c *Collector) ObserveRequest(operation string, status int, latency time.Duration) {
+	c.requests.WithLabelValues(operation, code(status)).Inc()
+	c.latency.WithLabelValues(operation).Observe(latency.Seconds())
+}
+
+// IncRetry implements client.Metrics
+func (c *Collector) IncRetry(operation string) {
+	c.retries.WithLabelValues(operation).Inc()
+}
+
+// IncReauthentication implements client.Metrics
+func (c *Collector) IncReauthentication() {
+	c.reauthentications.Inc()
+}
+
+// SetTokenExpiry implements client.Metrics
+func (c *Collector) SetTokenExpiry(expiresAt time.Time) {
+	c.tokenExpiry.Set(float64(expiresAt.UnixNano()) / 1e9)
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.requests.Describe(ch)
+	c.latency.Describe(ch)
+	c.retries.Describe(ch)
+	c.reauthentications.Describe(ch)
+	c.tokenExpiry.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.requests.Collect(ch)
+	c.latency.Collect(ch)
+	c.retries.Collect(ch)
+	c.reauthentications.Collect(ch)
+	c.tokenExpiry.Collect(ch)
+}
@@ -0,0 +1,40 @@
+package prommetrics
+
+import (
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Path: "/admin/organizations", Status: http.StatusServiceUnavailable, Times: 1})
+
+	metrics := New("visualization")
+	registry := prometheus.NewRegistry()
+	assert.Equal(t, nil, registry.Register(metrics), "registered")
+
+	api, _ := client.NewVisualizationClient(s.URL, http.Client{}, "token",
+		client.WithMetrics(metrics), client.WithRetries(1))
+	_, err := api.GetOrganizations()
+	assert.Equal(t, err, nil, "no error")
+	_, err = api.GetOrganizationID("42")
+	assert.NotNil(t, err, "not found")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GetOrganizations", "503")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GetOrganizations", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GetOrganizationID", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.retries.WithLabelValues("GetOrganizations")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.reauthentications), "token reused")
+	assert.True(t, testutil.ToFloat64(metrics.tokenExpiry) > 0, "token expiry set")
+
+	count, err := testutil.GatherAndCount(registry)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, 10, count, "4 request counters, 3 histograms, retries, reauthentications and expiry")
+}
@@ -0,0 +1,161 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Format output format of a plan
+type Format string
+
+// Supported plan formats
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatDiff  Format = "diff"
+)
+
+// Summary counts the operations of a plan by kind of change
+type Summary struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+// String describes the summary like "Plan: 1 to create, 0 to update, 2 to delete."
+func (s Summary) String() string {
+	return fmt.Sprintf("Plan: %d to create, %d to update, %d to delete.", s.Create, s.Update, s.Delete)
+}
+
+// Summary counts the operations of the plan
+func (p Plan) Summary() (summary Summary) {
+	for _, op := range p.Operations {
+		switch op.Action {
+		case CreateOrganization, CreateUser, AddMember:
+			summary.Create++
+		case UpdateMember:
+			summary.Update++
+		case RemoveMember, DeleteOrganization:
+			summary.Delete++
+		}
+	}
+	return
+}
+
+// Write renders the plan in the given format.
+// An empty format defaults to FormatTable.
+func (p Plan) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatTable, "":
+		return p.writeTable(w)
+	case FormatJSON:
+		return p.writeJSON(w)
+	case FormatDiff:
+		return p.writeDiff(w)
+	}
+	return fmt.Errorf("unknown plan format %q", format)
+}
+
+func (p Plan) writeTable(w io.Writer) error {
+	if p.Empty() {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tORGANIZATION\tLOGIN\tROLE")
+	for _, op := range p.Operations {
+		role := op.Role
+		switch {
+		case op.Action == UpdateMember:
+			role = op.OldRole + " -> " + op.Role
+		case role == "":
+			role = op.OldRole
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", op.Action, dash(op.Organization), dash(op.Login), dash(role))
+	}
+	err := tw.Flush()
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "\n%s\n", p.Summary())
+	return err
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func (p Plan) writeJSON(w io.Writer) error {
+	operations := p.Operations
+	if operations == nil {
+		operations = []Operation{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Operations []Operation `json:"operations"`
+		Summary    Summary     `json:"summary"`
+	}{operations, p.Summary()})
+}
+
+// writeDiff renders the plan like a unified diff of the tenancy:
+// users first, then one hunk per organization in plan order.
+func (p Plan) writeDiff(w io.Writer) error {
+	var users []string
+	var orgs []string
+	hunks := map[string][]string{}
+
+	hunk := func(org string, line string) {
+		if _, ok := hunks[org]; !ok {
+			orgs = append(orgs, org)
+		}
+		hunks[org] = append(hunks[org], line)
+	}
+
+	for _, op := range p.Operations {
+		switch op.Action {
+		case CreateUser:
+			users = append(users, "+user "+op.Login)
+		case CreateOrganization:
+			hunk(op.Organization, "+organization "+op.Organization)
+		case DeleteOrganization:
+			hunk(op.Organization, "-organization "+op.Organization)
+		case AddMember:
+			hunk(op.Organization, fmt.Sprintf("+member %s %s", op.Login, op.Role))
+		case UpdateMember:
+			hunk(op.Organization, fmt.Sprintf("-member %s %s", op.Login, op.OldRole))
+			hunk(op.Organization, fmt.Sprintf("+member %s %s", op.Login, op.Role))
+		case RemoveMember:
+			hunk(op.Organization, fmt.Sprintf("-member %s %s", op.Login, op.OldRole))
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "--- current\n+++ desired")
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		fmt.Fprintln(w, "@@ users @@")
+		for _, line := range users {
+			fmt.Fprintln(w, line)
+		}
+	}
+	for _, org := range orgs {
+		fmt.Fprintf(w, "@@ organization %s @@\n", org)
+		for _, line := range hunks[org] {
+			_, err = fmt.Fprintln(w, line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package reconcile
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var formatPlan = Plan{Operations: []Operation{
+	{Action: CreateOrganization, Organization: "tenant-a"},
+	{Action: CreateUser, Login: "bob"},
+	{Action: AddMember, Organization: "tenant-a", Login: "bob", Role: "Viewer"},
+	{Action: UpdateMember, Organization: "tenant-b", OrgID: "2", Login: "alice", UserID: "1", Role: "Admin", OldRole: "Editor"},
+	{Action: RemoveMember, Organization: "tenant-b", OrgID: "2", Login: "carol", UserID: "3", OldRole: "Viewer"},
+}}
+
+func TestWriteTable(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := formatPlan.Write(out, FormatTable)
+	assert.Nil(t, err)
+	assert.Equal(t, ""+
+		"ACTION               ORGANIZATION  LOGIN  ROLE\n"+
+		"create-organization  tenant-a      -      -\n"+
+		"create-user          -             bob    -\n"+
+		"add-member           tenant-a      bob    Viewer\n"+
+		"update-member        tenant-b      alice  Editor -> Admin\n"+
+		"remove-member        tenant-b      carol  Viewer\n"+
+		"\n"+
+		"Plan: 3 to create, 1 to update, 1 to delete.\n", out.String())
+
+	out.Reset()
+	err = Plan{}.Write(out, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "No changes.\n", out.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := formatPlan.Write(out, FormatJSON)
+	assert.Nil(t, err)
+
+	var decoded struct {
+		Operations []Operation `json:"operations"`
+		Summary    Summary     `json:"summary"`
+	}
+	err = json.Unmarshal(out.Bytes(), &decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, formatPlan.Operations, decoded.Operations)
+	assert.Equal(t, Summary{Create: 3, Update: 1, Delete: 1}, decoded.Summary)
+}
+
+func TestWriteDiff(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := formatPlan.Write(out, FormatDiff)
+	assert.Nil(t, err)
+	assert.Equal(t, ""+
+		"--- current\n"+
+		"+++ desired\n"+
+		"@@ users @@\n"+
+		"+user bob\n"+
+		"@@ organization tenant-a @@\n"+
+		"+organization tenant-a\n"+
+		"+member bob Viewer\n"+
+		"@@ organization tenant-b @@\n"+
+		"-member alice Editor\n"+
+		"+member alice Admin\n"+
+		"-member carol Viewer\n", out.String())
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	err := formatPlan.Write(&bytes.Buffer{}, Format("xml"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,303 @@
+// Package reconcile converges organizations, users and memberships of the
+// visualization API to a declarative desired state.
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// API is the part of VisualizationClient used by the reconciler
+type API interface {
+	GetOrganizations() ([]client.Org, error)
+	GetUsers() ([]client.User, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+	CreateOrganization(org client.Org) (client.Org, error)
+	CreateUser(user client.User) (client.User, error)
+	CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error)
+	DeleteOrganizationUser(userID string, orgID string) (client.UserInOrganization, error)
+	DeleteOrganization(ID string) (client.Org, error)
+}
+
+// Action kind of change performed by an Operation
+type Action string
+
+// Actions in the order they are applied
+const (
+	CreateOrganization Action = "create-organization"
+	CreateUser         Action = "create-user"
+	AddMember          Action = "add-member"
+	UpdateMember       Action = "update-member"
+	RemoveMember       Action = "remove-member"
+	DeleteOrganization Action = "delete-organization"
+)
+
+// Operation single change of a Plan
+type Operation struct {
+	Action       Action `json:"action"`
+	Organization string `json:"organization,omitempty"`
+	OrgID        string `json:"orgID,omitempty"`
+	Login        string `json:"login,omitempty"`
+	UserID       string `json:"userID,omitempty"`
+	Role         string `json:"role,omitempty"`
+	OldRole      string `json:"oldRole,omitempty"`
+
+	member Member
+}
+
+// String describes the operation on a single line
+func (o Operation) String() string {
+	switch o.Action {
+	case CreateOrganization, DeleteOrganization:
+		return fmt.Sprintf("%s %s", o.Action, o.Organization)
+	case CreateUser:
+		return fmt.Sprintf("%s %s", o.Action, o.Login)
+	case UpdateMember:
+		return fmt.Sprintf("%s %s/%s %s -> %s", o.Action, o.Organization, o.Login, o.OldRole, o.Role)
+	case RemoveMember:
+		return fmt.Sprintf("%s %s/%s", o.Action, o.Organization, o.Login)
+	}
+	return fmt.Sprintf("%s %s/%s %s", o.Action, o.Organization, o.Login, o.Role)
+}
+
+// Plan ordered list of operations converging the current state to the desired one
+type Plan struct {
+	Operations []Operation `json:"operations"`
+}
+
+// Empty reports whether the plan has nothing to do
+func (p Plan) Empty() bool {
+	return len(p.Operations) == 0
+}
+
+// ApplyError reports the operation a plan stopped at
+type ApplyError struct {
+	Operation Operation
+	Applied   int
+	Err       error
+}
+
+// Error generate a error message.
+func (e ApplyError) Error() string {
+	return fmt.Sprintf("%s failed after %d applied operations: %v", e.Operation, e.Applied, e.Err)
+}
+
+// Options tune the reconciler behaviour
+type Options struct {
+	// PruneOrganizations deletes organizations absent from the desired state
+	PruneOrganizations bool
+	// PreserveLogins are never removed from organizations (e.g. the admin user)
+	PreserveLogins []string
+	// DryRun only prints the plan to Out without applying it
+	DryRun bool
+	Out    io.Writer
+	// Format of the printed plan, FormatTable by default
+	Format Format
+}
+
+// Reconciler diffs a desired state against the API and applies the result
+type Reconciler struct {
+	api  API
+	opts Options
+}
+
+// New returns a reconciler working on api
+func New(api API, opts Options) *Reconciler {
+	return &Reconciler{api: api, opts: opts}
+}
+
+// Plan computes the operations needed to converge to the desired state
+func (r *Reconciler) Plan(desired State) (plan Plan, err error) {
+	err = desired.Validate()
+	if err != nil {
+		return
+	}
+
+	orgs, err := r.api.GetOrganizations()
+	if err != nil {
+		return
+	}
+	users, err := r.api.GetUsers()
+	if err != nil {
+		return
+	}
+
+	existingOrgs := map[string]client.Org{}
+	for _, org := range orgs {
+		existingOrgs[org.Name] = org
+	}
+	existingUsers := map[string]client.User{}
+	for _, user := range users {
+		existingUsers[user.Login] = user
+	}
+	preserved := map[string]bool{}
+	for _, login := range r.opts.PreserveLogins {
+		preserved[login] = true
+	}
+
+	var creates, members, removals []Operation
+	plannedUsers := map[string]bool{}
+	desiredOrgs := map[string]bool{}
+
+	for _, org := range desired.Organizations {
+		desiredOrgs[org.Name] = true
+
+		current := map[string]client.UserInOrganization{}
+		existing, found := existingOrgs[org.Name]
+		if found {
+			var orgUsers []client.UserInOrganization
+			orgUsers, err = r.api.GetOrganizationUsers(existing.OrganizationID)
+			if err != nil {
+				return Plan{}, err
+			}
+			for _, user := range orgUsers {
+				current[user.Login] = user
+			}
+		} else {
+			creates = append(creates, Operation{Action: CreateOrganization, Organization: org.Name})
+		}
+
+		wanted := map[string]bool{}
+		for _, member := range org.Users {
+			wanted[member.Login] = true
+
+			_, userExists := existingUsers[member.Login]
+			if !userExists && !plannedUsers[member.Login] {
+				plannedUsers[member.Login] = true
+				creates = append(creates, Operation{Action: CreateUser, Login: member.Login, member: member})
+			}
+
+			user, isMember := current[member.Login]
+			switch {
+			case !isMember:
+				members = append(members, Operation{Action: AddMember, Organization: org.Name,
+					OrgID: existing.OrganizationID, Login: member.Login, Role: member.Role, member: member})
+			case user.Role != member.Role:
+				members = append(members, Operation{Action: UpdateMember, Organization: org.Name,
+					OrgID: existing.OrganizationID, Login: member.Login, UserID: user.UserID,
+					Role: member.Role, OldRole: user.Role, member: member})
+			}
+		}
+
+		logins := make([]string, 0, len(current))
+		for login := range current {
+			logins = append(logins, login)
+		}
+		sort.Strings(logins)
+		for _, login := range logins {
+			if wanted[login] || preserved[login] {
+				continue
+			}
+			user := current[login]
+			removals = append(removals, Operation{Action: RemoveMember, Organization: org.Name,
+				OrgID: existing.OrganizationID, Login: login, UserID: user.UserID, OldRole: user.Role})
+		}
+	}
+
+	if r.opts.PruneOrganizations {
+		for _, org := range orgs {
+			if desiredOrgs[org.Name] {
+				continue
+			}
+			removals = append(removals, Operation{Action: DeleteOrganization,
+				Organization: org.Name, OrgID: org.OrganizationID})
+		}
+	}
+
+	plan.Operations = append(plan.Operations, creates...)
+	plan.Operations = append(plan.Operations, members...)
+	plan.Operations = append(plan.Operations, removals...)
+	return
+}
+
+// Apply executes the plan in order and stops at the first failing operation.
+// The returned ApplyError tells how many operations were already applied.
+func (r *Reconciler) Apply(plan Plan) error {
+	created := map[string]string{}
+
+	for i, op := range plan.Operations {
+		if op.OrgID == "" {
+			op.OrgID = created[op.Organization]
+		}
+
+		err := r.apply(op, created)
+		if err != nil {
+			return ApplyError{Operation: op, Applied: i, Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) apply(op Operation, created map[string]string) error {
+	switch op.Action {
+	case CreateOrganization:
+		org, err := r.api.CreateOrganization(client.Org{Name: op.Organization})
+		if err != nil {
+			return err
+		}
+		if org.OrganizationID == "" {
+			return fmt.Errorf("organization %q not found after creation", op.Organization)
+		}
+		created[op.Organization] = org.OrganizationID
+		return nil
+
+	case CreateUser:
+		_, err := r.api.CreateUser(client.User{Login: op.member.Login, Email: op.member.Email,
+			Name: op.member.Name, Password: op.member.Password})
+		return err
+
+	case AddMember:
+		_, err := r.api.CreateUserOrganization(op.OrgID, membership(op))
+		return err
+
+	case UpdateMember:
+		_, err := r.api.DeleteOrganizationUser(op.UserID, op.OrgID)
+		if err != nil {
+			return err
+		}
+		_, err = r.api.CreateUserOrganization(op.OrgID, membership(op))
+		if err != nil {
+			// the API cannot update memberships, restore the previous one
+			previous := client.UserInOrganization{OrgID: op.OrgID, UserID: op.UserID, Login: op.Login, Role: op.OldRole}
+			_, restoreErr := r.api.CreateUserOrganization(op.OrgID, previous)
+			return client.RoleChangeError{User: previous, Role: op.Role, Err: err, RestoreErr: restoreErr}
+		}
+		return nil
+
+	case RemoveMember:
+		_, err := r.api.DeleteOrganizationUser(op.UserID, op.OrgID)
+		return err
+
+	case DeleteOrganization:
+		_, err := r.api.DeleteOrganization(op.OrgID)
+		return err
+	}
+	return fmt.Errorf("unknown action %q", op.Action)
+}
+
+func membership(op Operation) client.UserInOrganization {
+	return client.UserInOrganization{OrgID: op.OrgID, Login: op.Login, Email: op.member.Email,
+		Password: op.member.Password, Role: op.Role}
+}
+
+// Reconcile plans the desired state and applies it.
+// In dry-run mode the plan is only written to Options.Out.
+func (r *Reconciler) Reconcile(desired State) (plan Plan, err error) {
+	plan, err = r.Plan(desired)
+	if err != nil {
+		return
+	}
+
+	if r.opts.DryRun {
+		if r.opts.Out != nil {
+			err = plan.Write(r.opts.Out, r.opts.Format)
+		}
+		return
+	}
+
+	err = r.Apply(plan)
+	return
+}
@@ -0,0 +1,262 @@
+package reconcile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI keeps the visualization state in memory
+type fakeAPI struct {
+	orgs    []client.Org
+	users   []client.User
+	members map[string][]client.UserInOrganization
+	failOn  string
+	calls   []string
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{members: map[string][]client.UserInOrganization{}}
+}
+
+func (f *fakeAPI) call(name string) error {
+	f.calls = append(f.calls, name)
+	if name == f.failOn {
+		return errors.New("boom")
+	}
+	return nil
+}
+
+func (f *fakeAPI) GetOrganizations() ([]client.Org, error) {
+	return f.orgs, nil
+}
+
+func (f *fakeAPI) GetUsers() ([]client.User, error) {
+	return f.users, nil
+}
+
+func (f *fakeAPI) GetOrganizationUsers(ID string) ([]client.UserInOrganization, error) {
+	return f.members[ID], nil
+}
+
+func (f *fakeAPI) CreateOrganization(org client.Org) (client.Org, error) {
+	if err := f.call("CreateOrganization " + org.Name); err != nil {
+		return client.Org{}, err
+	}
+	org.OrganizationID = fmt.Sprint(len(f.orgs) + 1)
+	f.orgs = append(f.orgs, org)
+	return org, nil
+}
+
+func (f *fakeAPI) CreateUser(user client.User) (client.User, error) {
+	if err := f.call("CreateUser " + user.Login); err != nil {
+		return client.User{}, err
+	}
+	user.UserID = fmt.Sprint(len(f.users) + 1)
+	f.users = append(f.users, user)
+	return user, nil
+}
+
+func (f *fakeAPI) CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error) {
+	if err := f.call("CreateUserOrganization " + OrgID + " " + user.Login + " " + user.Role); err != nil {
+		return client.UserInOrganization{}, err
+	}
+	for _, known := range f.users {
+		if known.Login == user.Login {
+			user.UserID = known.UserID
+		}
+	}
+	f.members[OrgID] = append(f.members[OrgID], user)
+	return user, nil
+}
+
+func (f *fakeAPI) DeleteOrganizationUser(userID string, orgID string) (client.UserInOrganization, error) {
+	if err := f.call("DeleteOrganizationUser " + userID + " " + orgID); err != nil {
+		return client.UserInOrganization{}, err
+	}
+	var kept []client.UserInOrganization
+	for _, user := range f.members[orgID] {
+		if user.UserID != userID {
+			kept = append(kept, user)
+		}
+	}
+	f.members[orgID] = kept
+	return client.UserInOrganization{}, nil
+}
+
+func (f *fakeAPI) DeleteOrganization(ID string) (client.Org, error) {
+	if err := f.call("DeleteOrganization " + ID); err != nil {
+		return client.Org{}, err
+	}
+	return client.Org{}, nil
+}
+
+const tenantDocument = `
+organizations:
+  - name: tenant-a
+    users:
+      - login: alice
+        email: alice@example.com
+        name: Alice
+        role: Admin
+      - login: bob
+        email: bob@example.com
+        name: Bob
+        role: Viewer
+`
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		description string
+		document    string
+		expectError bool
+	}{
+		{
+			description: "valid document",
+			document:    tenantDocument,
+		},
+		{
+			description: "invalid role",
+			document:    "organizations:\n  - name: a\n    users:\n      - login: x\n        role: viewer \n",
+			expectError: true,
+		},
+		{
+			description: "invalid email",
+			document:    "organizations:\n  - name: a\n    users:\n      - login: x\n        email: x.example.com\n        role: Viewer\n",
+			expectError: true,
+		},
+		{
+			description: "login with a space",
+			document:    "organizations:\n  - name: a\n    users:\n      - login: john doe\n        role: Viewer\n",
+			expectError: true,
+		},
+		{
+			description: "organization name with surrounding spaces",
+			document:    "organizations:\n  - name: \" a\"\n",
+			expectError: true,
+		},
+		{
+			description: "duplicated organization",
+			document:    "organizations:\n  - name: a\n  - name: a\n",
+			expectError: true,
+		},
+		{
+			description: "unknown field",
+			document:    "organisations:\n  - name: a\n",
+			expectError: true,
+		},
+	}
+	for _, testCase := range tests {
+		_, err := Load(strings.NewReader(testCase.document))
+		assert.Equal(t, testCase.expectError, err != nil, testCase.description)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	desired, err := Load(strings.NewReader(tenantDocument))
+	assert.Nil(t, err)
+
+	api := newFakeAPI()
+	api.orgs = []client.Org{{OrganizationID: "1", Name: "tenant-a"}, {OrganizationID: "2", Name: "stale"}}
+	api.users = []client.User{{UserID: "1", Login: "alice"}, {UserID: "3", Login: "carol"}}
+	api.members["1"] = []client.UserInOrganization{
+		{OrgID: "1", UserID: "1", Login: "alice", Role: "Viewer"},
+		{OrgID: "1", UserID: "3", Login: "carol", Role: "Editor"},
+	}
+
+	plan, err := New(api, Options{PruneOrganizations: true}).Plan(desired)
+	assert.Nil(t, err)
+
+	var lines []string
+	for _, op := range plan.Operations {
+		lines = append(lines, op.String())
+	}
+	assert.Equal(t, []string{
+		"create-user bob",
+		"update-member tenant-a/alice Viewer -> Admin",
+		"add-member tenant-a/bob Viewer",
+		"remove-member tenant-a/carol",
+		"delete-organization stale",
+	}, lines)
+}
+
+func TestReconcile(t *testing.T) {
+	desired, err := Load(strings.NewReader(tenantDocument))
+	assert.Nil(t, err)
+
+	api := newFakeAPI()
+	_, err = New(api, Options{}).Reconcile(desired)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"CreateOrganization tenant-a",
+		"CreateUser alice",
+		"CreateUser bob",
+		"CreateUserOrganization 1 alice Admin",
+		"CreateUserOrganization 1 bob Viewer",
+	}, api.calls)
+
+	// a second run has nothing left to do
+	plan, err := New(api, Options{}).Plan(desired)
+	assert.Nil(t, err)
+	assert.True(t, plan.Empty())
+}
+
+func TestReconcileDryRun(t *testing.T) {
+	desired, err := Load(strings.NewReader(tenantDocument))
+	assert.Nil(t, err)
+
+	api := newFakeAPI()
+	out := &bytes.Buffer{}
+	plan, err := New(api, Options{DryRun: true, Out: out}).Reconcile(desired)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(plan.Operations))
+	assert.Equal(t, 0, len(api.calls), "nothing applied")
+	assert.Contains(t, out.String(), "Plan: 5 to create, 0 to update, 0 to delete.")
+}
+
+func TestApplyStopsOnError(t *testing.T) {
+	desired, err := Load(strings.NewReader(tenantDocument))
+	assert.Nil(t, err)
+
+	api := newFakeAPI()
+	api.failOn = "CreateUser bob"
+	_, err = New(api, Options{}).Reconcile(desired)
+	applyErr, ok := err.(ApplyError)
+	assert.True(t, ok, "apply error")
+	assert.Equal(t, 2, applyErr.Applied)
+	assert.Equal(t, CreateUser, applyErr.Operation.Action)
+}
+
+func TestApplyRestoresRole(t *testing.T) {
+	desired, err := Load(strings.NewReader(tenantDocument))
+	assert.Nil(t, err)
+
+	api := newFakeAPI()
+	api.orgs = []client.Org{{OrganizationID: "1", Name: "tenant-a"}}
+	api.users = []client.User{{UserID: "1", Login: "alice"}, {UserID: "2", Login: "bob"}}
+	api.members["1"] = []client.UserInOrganization{
+		{OrgID: "1", UserID: "1", Login: "alice", Role: "Viewer"},
+		{OrgID: "1", UserID: "2", Login: "bob", Role: "Viewer"},
+	}
+	api.failOn = "CreateUserOrganization 1 alice Admin"
+
+	_, err = New(api, Options{}).Reconcile(desired)
+	applyErr, ok := err.(ApplyError)
+	assert.True(t, ok, "apply error")
+	assert.Equal(t, UpdateMember, applyErr.Operation.Action)
+	changeErr, ok := applyErr.Err.(client.RoleChangeError)
+	assert.True(t, ok, "role change error")
+	assert.True(t, changeErr.Member(), "previous role restored")
+	assert.Equal(t, []string{
+		"DeleteOrganizationUser 1 1",
+		"CreateUserOrganization 1 alice Admin",
+		"CreateUserOrganization 1 alice Viewer",
+	}, api.calls)
+	assert.Equal(t, "Viewer", api.members["1"][1].Role)
+	assert.Equal(t, "alice", api.members["1"][1].Login)
+}
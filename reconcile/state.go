@@ -0,0 +1,103 @@
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// State desired state document describing organizations and their users
+type State struct {
+	Organizations []Organization `json:"organizations" yaml:"organizations"`
+}
+
+// Organization desired organization with its members
+type Organization struct {
+	Name  string   `json:"name" yaml:"name"`
+	Users []Member `json:"users" yaml:"users"`
+}
+
+// Member desired user and the role it has in the organization
+type Member struct {
+	Login    string `json:"login" yaml:"login"`
+	Email    string `json:"email" yaml:"email"`
+	Name     string `json:"name" yaml:"name"`
+	Password string `json:"password" yaml:"password"`
+	Role     string `json:"role" yaml:"role"`
+}
+
+// Load reads a desired state document in YAML (or JSON) format
+func Load(r io.Reader) (state State, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	err = yaml.UnmarshalStrict(data, &state)
+	if err != nil {
+		return State{}, err
+	}
+
+	err = state.Validate()
+	if err != nil {
+		return State{}, err
+	}
+	return
+}
+
+// Validate checks that the document is consistent, so that Apply does not
+// fail halfway on a payload the API rejects.
+// Organization names must be unique and valid, every member needs a login, a
+// valid role and details the client accepts, and a login shared by several
+// organizations must describe the same user.
+func (s State) Validate() error {
+	orgs := map[string]bool{}
+	users := map[string]Member{}
+
+	for _, org := range s.Organizations {
+		if org.Name == "" {
+			return fmt.Errorf("organization without name")
+		}
+		err := client.Org{Name: org.Name}.Validate()
+		if err != nil {
+			return fmt.Errorf("organization %q: %v", org.Name, err)
+		}
+		if orgs[org.Name] {
+			return fmt.Errorf("organization %q declared twice", org.Name)
+		}
+		orgs[org.Name] = true
+
+		logins := map[string]bool{}
+		for _, member := range org.Users {
+			if member.Login == "" {
+				return fmt.Errorf("organization %q: user without login", org.Name)
+			}
+			if logins[member.Login] {
+				return fmt.Errorf("organization %q: user %q declared twice", org.Name, member.Login)
+			}
+			logins[member.Login] = true
+
+			switch member.Role {
+			case client.RoleViewer, client.RoleEditor, client.RoleAdmin:
+			default:
+				return fmt.Errorf("organization %q: user %q has invalid role %q", org.Name, member.Login, member.Role)
+			}
+			err = client.User{Login: member.Login, Email: member.Email, Name: member.Name}.Validate()
+			if err != nil {
+				return fmt.Errorf("organization %q: user %q: %v", org.Name, member.Login, err)
+			}
+
+			if known, ok := users[member.Login]; ok {
+				if known.Email != member.Email || known.Name != member.Name {
+					return fmt.Errorf("user %q declared with different details", member.Login)
+				}
+				continue
+			}
+			users[member.Login] = member
+		}
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package recorder records the HTTP interactions of a client to a golden
+// file and replays them offline.
+//
+// The recorder is an http.RoundTripper, plugged into NewVisualizationClient
+// through the http.Client parameter:
+//
+//	rec, err := recorder.New("testdata/tenants.json", recorder.ModeReplay)
+//	api, err := client.NewVisualizationClient(url, rec.Client(), token)
+//
+// Credentials never reach the golden file: they are redacted as in the
+// client logs, with client.RedactHeader and client.RedactBody.
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// Redacted replaces the scrubbed secrets
+const Redacted = client.Redacted
+
+// Mode of a Recorder
+type Mode int
+
+// Recorder modes
+const (
+	// ModeRecord forwards requests to Transport and records them
+	ModeRecord Mode = iota
+	// ModeReplay answers requests from the golden file
+	ModeReplay
+)
+
+// Request recorded request
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response recorded response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette content of a golden file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder records or replays HTTP interactions
+type Recorder struct {
+	// Transport performs the requests in ModeRecord, http.DefaultTransport when nil
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New returns a recorder for the golden file at path.
+// In ModeReplay the file is loaded immediately.
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &r.cassette)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Client returns an http.Client using the recorder as transport
+func (r *Recorder) Client() http.Client {
+	return http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the golden file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper.
+// The request of the caller is left untouched, its body is read from a clone.
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	recorded := Request{
+		Method: request.Method,
+		URL:    request.URL.RequestURI(),
+		Header: scrubHeader(request.Header),
+		Body:   client.RedactBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, recorded)
+	}
+	return r.record(request, recorded)
+}
+
+func (r *Recorder) record(request *http.Request, recorded Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Header:     scrubHeader(response.Header),
+			Body:       client.RedactBody(body),
+		},
+	})
+	r.mu.Unlock()
+	return response, nil
+}
+
+// replay answers with the first unused interaction matching the method, URL
+// and body of the request. Once all matching interactions are used the last
+// one is served again, since re-authentications are not deterministic.
+func (r *Recorder) replay(request *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	match := -1
+	for i, interaction := range r.cassette.Interactions {
+		candidate := interaction.Request
+		if candidate.Method != recorded.Method || candidate.URL != recorded.URL || candidate.Body != recorded.Body {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match < 0 {
+		return nil, fmt.Errorf("recorder: no recorded interaction for %s %s", recorded.Method, recorded.URL)
+	}
+	r.used[match] = true
+
+	interaction := r.cassette.Interactions[match].Response
+	header := http.Header{}
+	for key, values := range interaction.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.Body)),
+		ContentLength: int64(len(interaction.Body)),
+		Request:       request,
+	}, nil
+}
+
+func scrubHeader(header http.Header) http.Header {
+	scrubbed := client.RedactHeader(header)
+	scrubbed.Del("Date")
+	return scrubbed
+}
@@ -0,0 +1,105 @@
+package recorder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	assert.Equal(t, err, nil, "no error")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "golden.json")
+
+	s := visualizationtest.NewServer("openstack-secret")
+	org := s.AddOrganization("tenant")
+	s.AddUser(client.User{Login: "alice", Name: "Alice", Email: "alice@example.com"})
+
+	rec, err := New(path, ModeRecord)
+	assert.Equal(t, err, nil, "no error")
+	api, _ := client.NewVisualizationClient(s.URL, rec.Client(), "openstack-secret")
+	user, err := api.CreateUser(client.User{Login: "bob", Name: "Bob", Email: "bob@example.com", Password: "bob-secret"})
+	assert.Equal(t, err, nil, "no error")
+	orgs, err := api.GetOrganizations()
+	assert.Equal(t, err, nil, "no error")
+	_, err = api.GetOrganizationID("42")
+	assert.NotNil(t, err, "not found recorded")
+	assert.Equal(t, nil, rec.Save(), "saved")
+	s.Close()
+
+	golden, err := ioutil.ReadFile(path)
+	assert.Equal(t, err, nil, "no error")
+	assert.NotContains(t, string(golden), "openstack-secret")
+	assert.NotContains(t, string(golden), "bob-secret")
+	assert.NotContains(t, string(golden), "jwt-")
+	assert.Contains(t, string(golden), Redacted)
+
+	rec, err = New(path, ModeReplay)
+	assert.Equal(t, err, nil, "no error")
+	api, _ = client.NewVisualizationClient(s.URL, rec.Client(), "openstack-secret")
+
+	replayedUser, err := api.CreateUser(client.User{Login: "bob", Name: "Bob", Email: "bob@example.com", Password: "bob-secret"})
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, user, replayedUser)
+	replayedOrgs, err := api.GetOrganizations()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, orgs, replayedOrgs)
+	assert.Equal(t, []client.Org{org}, replayedOrgs)
+	_, err = api.GetOrganizationID("42")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+
+	_, err = api.GetUserID("2")
+	assert.Contains(t, err.Error(), "no recorded interaction for GET /admin/users/2")
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		description string
+		body        string
+		expected    string
+	}{
+		{
+			description: "nested secrets",
+			body:        `{"jwt":"abc","token":{"isAdmin":true},"users":[{"login":"a","Password":"p"}]}`,
+			expected:    `{"jwt":"[REDACTED]","token":{"isAdmin":true},"users":[{"Password":"[REDACTED]","login":"a"}]}`,
+		},
+		{
+			description: "empty password kept",
+			body:        `{"login":"a","password":""}`,
+			expected:    `{"login":"a","password":""}`,
+		},
+		{
+			description: "not JSON",
+			body:        "password=p",
+			expected:    "password=p",
+		},
+	}
+	for _, testCase := range tests {
+		assert.Equal(t, testCase.expected, client.RedactBody([]byte(testCase.body)), testCase.description)
+	}
+}
+
+func TestRoundTripKeepsRequest(t *testing.T) {
+	s := visualizationtest.NewServer("openstack-secret")
+	defer s.Close()
+	rec, err := New("", ModeRecord)
+	assert.Equal(t, err, nil, "no error")
+
+	body := ioutil.NopCloser(strings.NewReader(`{"password":"p"}`))
+	request, err := http.NewRequest("POST", s.URL+"/auth/openstack", body)
+	assert.Equal(t, err, nil, "no error")
+	request.Header.Set("X-OpenStack-Auth-Token", "openstack-secret")
+	_, err = rec.RoundTrip(request)
+	assert.Equal(t, err, nil, "no error")
+	assert.True(t, request.Body == body, "body of the caller kept")
+	assert.Equal(t, "openstack-secret", request.Header.Get("X-OpenStack-Auth-Token"))
+	assert.Equal(t, `{"password":"[REDACTED]"}`, rec.Interactions()[0].Request.Body)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Default delays between retries, see WithBackoff
+const (
+	DefaultBackoff    = 100 * time.Millisecond
+	DefaultMaxBackoff = 5 * time.Second
+)
+
+// retryPolicy how failed requests are retried
+type retryPolicy struct {
+	retries       int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	nonIdempotent bool
+}
+
+// retryable reports whether a failed attempt is worth retrying.
+// Status is zero when no response was received.
+func (p retryPolicy) retryable(method string, status int, withAuth bool) bool {
+	if status == 401 && !withAuth {
+		// rejected before being processed, retried after re-authenticating
+		return true
+	}
+	if status != 0 && status < 500 {
+		return false
+	}
+	// a POST may have been processed before failing, retrying it could
+	// create duplicates. Authenticating creates nothing.
+	return method != "POST" || withAuth || p.nonIdempotent
+}
+
+// delay returns the wait before the retry following attempt: exponential
+// from backoff up to maxBackoff, half of it random so that clients failing
+// together do not retry together
+func (p retryPolicy) delay(attempt int) time.Duration {
+	delay := p.maxBackoff
+	if attempt < 32 {
+		if exponential := p.backoff << uint(attempt); exponential > 0 && exponential < delay {
+			delay = exponential
+		}
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// sleep waits for d, returning early with the error of ctx when it is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		description string
+		options     []Option
+		method      string
+		failures    int
+		status      int
+		expectError bool
+		expectCalls int
+	}{
+		{
+			description: "no retry by default",
+			method:      "GET",
+			failures:    1,
+			status:      http.StatusServiceUnavailable,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			description: "server error retried",
+			options:     []Option{WithRetries(2)},
+			method:      "GET",
+			failures:    2,
+			status:      http.StatusServiceUnavailable,
+			expectCalls: 3,
+		},
+		{
+			description: "retries exhausted",
+			options:     []Option{WithRetries(1)},
+			method:      "GET",
+			failures:    2,
+			status:      http.StatusBadGateway,
+			expectError: true,
+			expectCalls: 2,
+		},
+		{
+			description: "unauthorized retried after authentication",
+			options:     []Option{WithRetries(1)},
+			method:      "GET",
+			failures:    1,
+			status:      http.StatusUnauthorized,
+			expectCalls: 2,
+		},
+		{
+			description: "not found not retried",
+			options:     []Option{WithRetries(3)},
+			method:      "GET",
+			failures:    1,
+			status:      http.StatusNotFound,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			description: "POST not retried on server error",
+			options:     []Option{WithRetries(3)},
+			method:      "POST",
+			failures:    1,
+			status:      http.StatusServiceUnavailable,
+			expectError: true,
+			expectCalls: 1,
+		},
+		{
+			description: "POST retried on unauthorized",
+			options:     []Option{WithRetries(1)},
+			method:      "POST",
+			failures:    1,
+			status:      http.StatusUnauthorized,
+			expectCalls: 2,
+		},
+		{
+			description: "POST retried when allowed",
+			options:     []Option{WithRetries(1), WithNonIdempotentRetries()},
+			method:      "POST",
+			failures:    1,
+			status:      http.StatusServiceUnavailable,
+			expectCalls: 2,
+		},
+	}
+	for _, testCase := range tests {
+		calls := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/auth/openstack" {
+				fmt.Fprint(w, `{"jwt":"jwt"}`)
+				return
+			}
+			if r.Method == testCase.method {
+				calls++
+				if calls <= testCase.failures {
+					w.WriteHeader(testCase.status)
+					fmt.Fprint(w, `{}`)
+					return
+				}
+			}
+			fmt.Fprint(w, `[{"organizationID":"1","name":"tenant"}]`)
+		}))
+
+		options := append([]Option{WithBackoff(time.Millisecond, time.Millisecond)}, testCase.options...)
+		client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", options...)
+		var err error
+		if testCase.method == "POST" {
+			_, err = client.CreateOrganization(Org{Name: "tenant"})
+		} else {
+			_, err = client.GetOrganizations()
+		}
+		assert.Equal(t, testCase.expectError, err != nil, testCase.description)
+		assert.Equal(t, testCase.expectCalls, calls, testCase.description)
+		ts.Close()
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := retryPolicy{backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{0, 50 * time.Millisecond, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond, 800 * time.Millisecond},
+		{4, 500 * time.Millisecond, time.Second},
+		{100, 500 * time.Millisecond, time.Second},
+	}
+	for _, testCase := range tests {
+		for i := 0; i < 20; i++ {
+			delay := policy.delay(testCase.attempt)
+			assert.True(t, delay >= testCase.min && delay < testCase.max,
+				"attempt %d waits %v, not in [%v, %v)", testCase.attempt, delay, testCase.min, testCase.max)
+		}
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt"}`)
+			return
+		}
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithRetries(3), WithBackoff(time.Hour, time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := client.getOrganizations(ctx)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 1, calls, "no retry once the context is done")
+	assert.True(t, time.Since(start) < time.Minute, "backoff interrupted")
+}
@@ -0,0 +1,204 @@
+// Package safedelete deletes organizations after previewing what the
+// deletion destroys.
+//
+// Preview lists the members and dashboards of the organization and derives a
+// confirmation token from them. Delete only deletes when given the token of
+// the current impact, so nothing changed between the preview and the
+// deletion, and can export the organization to a snapshot first:
+//
+//	impact, err := safedelete.Preview(api, orgID, opts)
+//	impact.Write(os.Stdout)
+//	// after the operator confirmed
+//	_, err = safedelete.Delete(api, orgID, impact.ConfirmationToken(), opts)
+package safedelete
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/snapshot"
+)
+
+// API is the part of VisualizationClient used to delete organizations
+type API interface {
+	GetUsers() ([]client.User, error)
+	GetOrganizationID(OrgID string) (client.Org, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+	DeleteOrganization(ID string) (client.Org, error)
+}
+
+// ErrConfirmation returned when the confirmation does not match the impact
+var ErrConfirmation = errors.New("confirmation does not match the impact of the deletion")
+
+// ErrProtected returned when deleting a protected organization
+var ErrProtected = errors.New("organization is protected")
+
+// DeleteError returned when the request deleting the organization failed.
+// The organization may have been deleted anyway, for example when the answer
+// was lost, so its export must be kept.
+type DeleteError struct {
+	OrgID string
+	Err   error
+}
+
+// Error generate a error message.
+func (e DeleteError) Error() string {
+	return fmt.Sprintf("deleting organization %s: %v", e.OrgID, e.Err)
+}
+
+// Options of a deletion
+type Options struct {
+	// Dashboards lists the dashboards destroyed with the organization when set
+	Dashboards snapshot.DashboardLister
+	// Protected names of organizations never deleted (e.g. "Main Org.")
+	Protected []string
+	// Export receives a snapshot of the organization before it is deleted.
+	// When it has a Sync method, as *os.File does, it is synced before
+	// deleting.
+	Export io.Writer
+	// ExportFormat of the snapshot, snapshot.FormatJSON when empty
+	ExportFormat snapshot.Format
+}
+
+// Impact what the deletion of an organization destroys
+type Impact struct {
+	Organization client.Org                  `json:"organization"`
+	Members      []client.UserInOrganization `json:"members"`
+	Dashboards   []snapshot.Dashboard        `json:"dashboards"`
+}
+
+// ConfirmationToken identifies the impact.
+// Delete only deletes when given the token of the current impact.
+func (i Impact) ConfirmationToken() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "organization:%s:%s\n", i.Organization.OrganizationID, i.Organization.Name)
+	for _, member := range i.Members {
+		fmt.Fprintf(hash, "member:%s:%s\n", member.UserID, member.Role)
+	}
+	for _, dashboard := range i.Dashboards {
+		fmt.Fprintf(hash, "dashboard:%s\n", dashboard.UID)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:12]
+}
+
+// Write prints the impact for humans
+func (i Impact) Write(w io.Writer) error {
+	fmt.Fprintf(w, "organization %s (%s) will be deleted with %d members and %d dashboards\n",
+		i.Organization.Name, i.Organization.OrganizationID, len(i.Members), len(i.Dashboards))
+	for _, member := range i.Members {
+		fmt.Fprintf(w, "member %s (%s): %s\n", member.Login, member.UserID, member.Role)
+	}
+	for _, dashboard := range i.Dashboards {
+		fmt.Fprintf(w, "dashboard %s (%s)\n", dashboard.Title, dashboard.UID)
+	}
+	_, err := fmt.Fprintf(w, "confirmation token: %s\n", i.ConfirmationToken())
+	return err
+}
+
+type byUserID []client.UserInOrganization
+
+func (u byUserID) Len() int           { return len(u) }
+func (u byUserID) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u byUserID) Less(i, j int) bool { return u[i].UserID < u[j].UserID }
+
+type byUID []snapshot.Dashboard
+
+func (d byUID) Len() int           { return len(d) }
+func (d byUID) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d byUID) Less(i, j int) bool { return d[i].UID < d[j].UID }
+
+// Preview returns what deleting the organization with ID orgID destroys
+func Preview(api API, orgID string, opts Options) (impact Impact, err error) {
+	impact.Organization, err = api.GetOrganizationID(orgID)
+	if err != nil {
+		return
+	}
+	for _, name := range opts.Protected {
+		if impact.Organization.Name == name {
+			return Impact{}, ErrProtected
+		}
+	}
+
+	members, err := api.GetOrganizationUsers(orgID)
+	if err != nil {
+		return Impact{}, err
+	}
+	impact.Members = append([]client.UserInOrganization{}, members...)
+	sort.Sort(byUserID(impact.Members))
+
+	impact.Dashboards = []snapshot.Dashboard{}
+	if opts.Dashboards != nil {
+		dashboards, err := opts.Dashboards.GetOrganizationDashboards(orgID)
+		if err != nil {
+			return Impact{}, err
+		}
+		impact.Dashboards = append(impact.Dashboards, dashboards...)
+		sort.Sort(byUID(impact.Dashboards))
+	}
+	return
+}
+
+// Delete deletes the organization with ID orgID when confirm is the token of
+// its current impact, after exporting it when opts.Export is set.
+// It returns the impact of the deletion. Any error but a DeleteError means
+// the deletion was not requested.
+func Delete(api API, orgID string, confirm string, opts Options) (impact Impact, err error) {
+	impact, err = Preview(api, orgID, opts)
+	if err != nil {
+		return
+	}
+	if confirm != impact.ConfirmationToken() {
+		return impact, ErrConfirmation
+	}
+
+	if opts.Export != nil {
+		err = export(api, impact, opts)
+		if err != nil {
+			return impact, fmt.Errorf("export before deletion failed, nothing deleted: %v", err)
+		}
+	}
+
+	_, err = api.DeleteOrganization(orgID)
+	if err != nil {
+		return impact, DeleteError{OrgID: orgID, Err: err}
+	}
+	return
+}
+
+// export writes a snapshot of the organization of impact and its members
+func export(api API, impact Impact, opts Options) error {
+	users, err := api.GetUsers()
+	if err != nil {
+		return err
+	}
+	byLogin := map[string]client.User{}
+	for _, user := range users {
+		byLogin[user.Login] = user
+	}
+
+	org := snapshot.Organization{Name: impact.Organization.Name, Members: []snapshot.Member{}}
+	if opts.Dashboards != nil {
+		org.Dashboards = impact.Dashboards
+	}
+	snap := snapshot.Snapshot{Version: snapshot.Version, Organizations: []snapshot.Organization{org}, Users: []snapshot.User{}}
+	for _, member := range impact.Members {
+		user := byLogin[member.Login]
+		snap.Organizations[0].Members = append(snap.Organizations[0].Members, snapshot.Member{Login: member.Login, Role: member.Role})
+		snap.Users = append(snap.Users, snapshot.User{Login: member.Login, Email: member.Email, Name: user.Name})
+	}
+	err = snap.Write(opts.Export, opts.ExportFormat)
+	if err != nil {
+		return err
+	}
+	if syncer, ok := opts.Export.(interface {
+		Sync() error
+	}); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
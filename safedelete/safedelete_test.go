@@ -0,0 +1,190 @@
+package safedelete
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/snapshot"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// dashboards fake snapshot.DashboardLister
+type dashboards map[string][]snapshot.Dashboard
+
+func (d dashboards) GetOrganizationDashboards(orgID string) ([]snapshot.Dashboard, error) {
+	if d == nil {
+		return nil, errors.New("dashboards unavailable")
+	}
+	return d[orgID], nil
+}
+
+func newTenancy() *visualizationtest.Server {
+	s := visualizationtest.NewServer("token")
+	s.AddOrganization("Main Org.")
+	org := s.AddOrganization("tenant")
+	bob := s.AddUser(client.User{Login: "bob", Email: "bob@example.com", Name: "Bob"})
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com", Name: "Alice"})
+	s.AddMember(org.OrganizationID, bob.UserID, client.RoleAdmin)
+	s.AddMember(org.OrganizationID, alice.UserID, client.RoleViewer)
+	return s
+}
+
+func TestPreview(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+	opts := Options{Dashboards: dashboards{"2": {{UID: "b", Title: "Beta"}, {UID: "a", Title: "Alpha"}}}}
+
+	impact, err := Preview(s.Client(), "2", opts)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tenant", impact.Organization.Name)
+	assert.Equal(t, []string{"3", "4"}, []string{impact.Members[0].UserID, impact.Members[1].UserID})
+	assert.Equal(t, []snapshot.Dashboard{{UID: "a", Title: "Alpha"}, {UID: "b", Title: "Beta"}}, impact.Dashboards)
+
+	token := impact.ConfirmationToken()
+	assert.Len(t, token, 12)
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, impact.Write(out))
+	assert.Equal(t, "organization tenant (2) will be deleted with 2 members and 2 dashboards\n"+
+		"member bob (3): Admin\n"+
+		"member alice (4): Viewer\n"+
+		"dashboard Alpha (a)\n"+
+		"dashboard Beta (b)\n"+
+		"confirmation token: "+token+"\n", out.String())
+
+	again, err := Preview(s.Client(), "2", opts)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, token, again.ConfirmationToken(), "token is stable")
+
+	s.AddMember("2", s.AddUser(client.User{Login: "carol"}).UserID, client.RoleEditor)
+	changed, err := Preview(s.Client(), "2", opts)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, token, changed.ConfirmationToken(), "token changes with the members")
+
+	impact, err = Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []snapshot.Dashboard{}, impact.Dashboards)
+
+	_, err = Preview(s.Client(), "2", Options{Dashboards: dashboards(nil)})
+	assert.Equal(t, "dashboards unavailable", err.Error())
+
+	_, err = Preview(s.Client(), "1", Options{Protected: []string{"Main Org."}})
+	assert.Equal(t, ErrProtected, err)
+
+	_, err = Preview(s.Client(), "42", Options{})
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+}
+
+func TestDelete(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	impact, err := Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+
+	tests := []struct {
+		description string
+		confirm     string
+		expectedErr error
+	}{
+		{"no confirmation", "", ErrConfirmation},
+		{"wrong confirmation", "000000000000", ErrConfirmation},
+	}
+	for _, testCase := range tests {
+		_, err := Delete(s.Client(), "2", testCase.confirm, Options{})
+		assert.Equal(t, testCase.expectedErr, err, testCase.description)
+		assert.Len(t, s.Organizations(), 2, testCase.description)
+	}
+
+	out := &bytes.Buffer{}
+	deleted, err := Delete(s.Client(), "2", impact.ConfirmationToken(), Options{Export: out, ExportFormat: snapshot.FormatYAML})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, impact, deleted)
+	assert.Equal(t, []client.Org{{OrganizationID: "1", Name: "Main Org."}}, s.Organizations())
+	assert.Equal(t, `version: 1
+organizations:
+- name: tenant
+  members:
+  - login: bob
+    role: Admin
+  - login: alice
+    role: Viewer
+users:
+- login: bob
+  email: bob@example.com
+  name: Bob
+- login: alice
+  email: alice@example.com
+  name: Alice
+`, out.String())
+}
+
+func TestDeleteChangedSincePreview(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	impact, err := Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+	s.AddMember("2", s.AddUser(client.User{Login: "carol"}).UserID, client.RoleEditor)
+
+	_, err = Delete(s.Client(), "2", impact.ConfirmationToken(), Options{})
+	assert.Equal(t, ErrConfirmation, err)
+	assert.Len(t, s.Organizations(), 2)
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestDeleteExportFailure(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	impact, err := Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+
+	_, err = Delete(s.Client(), "2", impact.ConfirmationToken(), Options{Export: failingWriter{}})
+	assert.Equal(t, "export before deletion failed, nothing deleted: disk full", err.Error())
+	assert.Len(t, s.Organizations(), 2)
+}
+
+// unsyncedWriter fails to sync what it was written
+type unsyncedWriter struct {
+	bytes.Buffer
+}
+
+func (*unsyncedWriter) Sync() error {
+	return errors.New("input/output error")
+}
+
+func TestDeleteExportNotSynced(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	impact, err := Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+
+	_, err = Delete(s.Client(), "2", impact.ConfirmationToken(), Options{Export: &unsyncedWriter{}})
+	assert.Equal(t, "export before deletion failed, nothing deleted: input/output error", err.Error())
+	assert.Len(t, s.Organizations(), 2)
+}
+
+func TestDeleteRequestFailure(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Method: "DELETE", Path: "/admin/organizations/2", Status: http.StatusBadGateway})
+
+	impact, err := Preview(s.Client(), "2", Options{})
+	assert.Equal(t, nil, err)
+
+	_, err = Delete(s.Client(), "2", impact.ConfirmationToken(), Options{})
+	deleteErr, ok := err.(DeleteError)
+	assert.True(t, ok, "deletion requested")
+	assert.Equal(t, "2", deleteErr.OrgID)
+}
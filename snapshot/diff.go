@@ -0,0 +1,205 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// FormatText human readable format of a diff
+const FormatText Format = "text"
+
+// ChangeKind kind of difference between two snapshots
+type ChangeKind string
+
+// Kinds of differences
+const (
+	Added       ChangeKind = "added"
+	Removed     ChangeKind = "removed"
+	RoleChanged ChangeKind = "role-changed"
+)
+
+// Change difference of an organization, user or member between two snapshots.
+// Organization is set for organizations and members, Login for users and
+// members, the roles for members.
+type Change struct {
+	Kind         ChangeKind `json:"kind"`
+	Object       Kind       `json:"object"`
+	Organization string     `json:"organization,omitempty"`
+	Login        string     `json:"login,omitempty"`
+	OldRole      string     `json:"oldRole,omitempty"`
+	NewRole      string     `json:"newRole,omitempty"`
+}
+
+// String describes the change like "~ member team/alice Viewer -> Admin"
+func (c Change) String() string {
+	var name string
+	switch c.Object {
+	case KindOrganization:
+		name = c.Organization
+	case KindUser:
+		name = c.Login
+	default:
+		name = c.Organization + "/" + c.Login
+	}
+	switch c.Kind {
+	case Added:
+		if c.Object == KindMember {
+			return fmt.Sprintf("+ %s %s (%s)", c.Object, name, c.NewRole)
+		}
+		return fmt.Sprintf("+ %s %s", c.Object, name)
+	case Removed:
+		if c.Object == KindMember {
+			return fmt.Sprintf("- %s %s (%s)", c.Object, name, c.OldRole)
+		}
+		return fmt.Sprintf("- %s %s", c.Object, name)
+	}
+	return fmt.Sprintf("~ %s %s %s -> %s", c.Object, name, c.OldRole, c.NewRole)
+}
+
+// Diff differences between two snapshots: organizations first, then users
+// and members, each sorted by name
+type Diff struct {
+	Changes []Change `json:"changes"`
+}
+
+// Empty reports whether the snapshots are equivalent
+func (d Diff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Count returns the number of changes of kind
+func (d Diff) Count(kind ChangeKind) (count int) {
+	for _, change := range d.Changes {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return
+}
+
+// Compare returns the differences from the snapshot from to the snapshot to.
+// The members of added and removed organizations are reported as added and
+// removed members, so the diff tells who gained or lost access.
+func Compare(from Snapshot, to Snapshot) (diff Diff) {
+	diff.Changes = []Change{}
+	fromOrgs, toOrgs := organizationsByName(from), organizationsByName(to)
+	for _, name := range union(orgNames(fromOrgs), orgNames(toOrgs)) {
+		_, inOld := fromOrgs[name]
+		_, inNew := toOrgs[name]
+		switch {
+		case !inOld:
+			diff.Changes = append(diff.Changes, Change{Kind: Added, Object: KindOrganization, Organization: name})
+		case !inNew:
+			diff.Changes = append(diff.Changes, Change{Kind: Removed, Object: KindOrganization, Organization: name})
+		}
+	}
+
+	fromUsers, toUsers := logins(from), logins(to)
+	for _, login := range union(setLogins(fromUsers), setLogins(toUsers)) {
+		switch {
+		case !fromUsers[login]:
+			diff.Changes = append(diff.Changes, Change{Kind: Added, Object: KindUser, Login: login})
+		case !toUsers[login]:
+			diff.Changes = append(diff.Changes, Change{Kind: Removed, Object: KindUser, Login: login})
+		}
+	}
+
+	for _, name := range union(orgNames(fromOrgs), orgNames(toOrgs)) {
+		fromRoles, toRoles := fromOrgs[name], toOrgs[name]
+		for _, login := range union(roleLogins(fromRoles), roleLogins(toRoles)) {
+			fromRole, toRole := fromRoles[login], toRoles[login]
+			change := Change{Object: KindMember, Organization: name, Login: login, OldRole: fromRole, NewRole: toRole}
+			switch {
+			case fromRole == "":
+				change.Kind = Added
+			case toRole == "":
+				change.Kind = Removed
+			case fromRole != toRole:
+				change.Kind = RoleChanged
+			default:
+				continue
+			}
+			diff.Changes = append(diff.Changes, change)
+		}
+	}
+	return
+}
+
+// organizationsByName returns the role of every member by login, by organization name
+func organizationsByName(snapshot Snapshot) map[string]map[string]string {
+	orgs := map[string]map[string]string{}
+	for _, org := range snapshot.Organizations {
+		roles := map[string]string{}
+		for _, member := range org.Members {
+			roles[member.Login] = member.Role
+		}
+		orgs[org.Name] = roles
+	}
+	return orgs
+}
+
+// logins returns the set of the logins of the users
+func logins(snapshot Snapshot) map[string]bool {
+	users := map[string]bool{}
+	for _, user := range snapshot.Users {
+		users[user.Login] = true
+	}
+	return users
+}
+
+func orgNames(orgs map[string]map[string]string) (names []string) {
+	for name := range orgs {
+		names = append(names, name)
+	}
+	return
+}
+
+func roleLogins(roles map[string]string) (names []string) {
+	for login := range roles {
+		names = append(names, login)
+	}
+	return
+}
+
+func setLogins(users map[string]bool) (names []string) {
+	for login := range users {
+		names = append(names, login)
+	}
+	return
+}
+
+// union returns the sorted names of a and b, without duplicates
+func union(a []string, b []string) (names []string) {
+	seen := map[string]bool{}
+	for _, name := range append(a, b...) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// Write renders the diff in format, FormatText when empty
+func (d Diff) Write(w io.Writer, format Format) error {
+	switch format {
+	case FormatText, "":
+		if d.Empty() {
+			_, err := fmt.Fprintln(w, "No changes.")
+			return err
+		}
+		for _, change := range d.Changes {
+			fmt.Fprintln(w, change)
+		}
+		_, err := fmt.Fprintf(w, "%d added, %d removed, %d role changes.\n", d.Count(Added), d.Count(Removed), d.Count(RoleChanged))
+		return err
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	}
+	return fmt.Errorf("unknown diff format %q", format)
+}
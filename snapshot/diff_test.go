@@ -0,0 +1,83 @@
+package snapshot
+
+import (
+	"bytes"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	from := Snapshot{
+		Version: Version,
+		Organizations: []Organization{
+			{Name: "main", Members: []Member{{Login: "alice", Role: client.RoleViewer}, {Login: "bob", Role: client.RoleEditor}}},
+			{Name: "legacy", Members: []Member{{Login: "bob", Role: client.RoleAdmin}}},
+		},
+		Users: []User{{Login: "alice"}, {Login: "bob"}},
+	}
+	to := Snapshot{
+		Version: Version,
+		Organizations: []Organization{
+			{Name: "main", Members: []Member{{Login: "alice", Role: client.RoleAdmin}, {Login: "carol", Role: client.RoleViewer}}},
+			{Name: "team", Members: []Member{{Login: "carol", Role: client.RoleEditor}}},
+		},
+		Users: []User{{Login: "alice"}, {Login: "carol"}},
+	}
+
+	diff := Compare(from, to)
+	assert.Equal(t, []Change{
+		{Kind: Removed, Object: KindOrganization, Organization: "legacy"},
+		{Kind: Added, Object: KindOrganization, Organization: "team"},
+		{Kind: Removed, Object: KindUser, Login: "bob"},
+		{Kind: Added, Object: KindUser, Login: "carol"},
+		{Kind: Removed, Object: KindMember, Organization: "legacy", Login: "bob", OldRole: client.RoleAdmin},
+		{Kind: RoleChanged, Object: KindMember, Organization: "main", Login: "alice", OldRole: client.RoleViewer, NewRole: client.RoleAdmin},
+		{Kind: Removed, Object: KindMember, Organization: "main", Login: "bob", OldRole: client.RoleEditor},
+		{Kind: Added, Object: KindMember, Organization: "main", Login: "carol", NewRole: client.RoleViewer},
+		{Kind: Added, Object: KindMember, Organization: "team", Login: "carol", NewRole: client.RoleEditor},
+	}, diff.Changes)
+
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, diff.Write(out, FormatText))
+	assert.Equal(t, `- organization legacy
++ organization team
+- user bob
++ user carol
+- member legacy/bob (Admin)
+~ member main/alice Viewer -> Admin
+- member main/bob (Editor)
++ member main/carol (Viewer)
++ member team/carol (Editor)
+4 added, 4 removed, 1 role changes.
+`, out.String())
+
+	assert.True(t, Compare(to, to).Empty())
+	out.Reset()
+	Compare(to, to).Write(out, FormatText)
+	assert.Equal(t, "No changes.\n", out.String())
+}
+
+func TestDiffJSON(t *testing.T) {
+	diff := Diff{Changes: []Change{
+		{Kind: RoleChanged, Object: KindMember, Organization: "main", Login: "alice", OldRole: client.RoleViewer, NewRole: client.RoleAdmin},
+	}}
+	out := &bytes.Buffer{}
+	assert.Equal(t, nil, diff.Write(out, FormatJSON))
+	assert.Equal(t, `{
+  "changes": [
+    {
+      "kind": "role-changed",
+      "object": "member",
+      "organization": "main",
+      "login": "alice",
+      "oldRole": "Viewer",
+      "newRole": "Admin"
+    }
+  ]
+}
+`, out.String())
+
+	assert.Equal(t, `unknown diff format "yaml"`, diff.Write(out, FormatYAML).Error())
+}
@@ -0,0 +1,218 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/internal/outcome"
+)
+
+// RestoreAPI is the part of VisualizationClient used by Restore
+type RestoreAPI interface {
+	API
+	CreateOrganization(org client.Org) (client.Org, error)
+	CreateUser(user client.User) (client.User, error)
+	CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error)
+}
+
+// Kind of object restored
+type Kind string
+
+// Kinds of restored objects
+const (
+	KindOrganization Kind = "organization"
+	KindUser         Kind = "user"
+	KindMember       Kind = "member"
+)
+
+// Status outcome of a restored object
+type Status = outcome.Status
+
+// Outcomes of the restored objects
+const (
+	Created = outcome.Created
+	Skipped = outcome.Skipped
+	Failed  = outcome.Failed
+)
+
+// Result of a restored object.
+// Name is the organization name, the user login or "organization/login".
+// Password is the one given to a created user, to hand over.
+type Result struct {
+	Kind     Kind   `json:"kind"`
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// Report results of a restore, organizations first, then users and members
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Count returns the number of results with status
+func (r Report) Count(status Status) int {
+	return outcome.Count(r.statuses(), status)
+}
+
+func (r Report) statuses() []Status {
+	statuses := make([]Status, len(r.Results))
+	for i, result := range r.Results {
+		statuses[i] = result.Status
+	}
+	return statuses
+}
+
+// Write prints the report as a table followed by a summary
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tSTATUS\tREASON")
+	for _, result := range r.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Kind, result.Name, result.Status, result.Reason)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	return outcome.WriteSummary(w, r.statuses())
+}
+
+// RestoreOptions of a restore
+type RestoreOptions struct {
+	// Password returns the password of a created user, random when nil.
+	// Either way it is reported in the Result of the user.
+	Password func(user User) (string, error)
+	// DryRun reports what would be done without changing anything
+	DryRun bool
+}
+
+// randomPassword default password of the created users
+func randomPassword(user User) (string, error) {
+	return outcome.RandomPassword()
+}
+
+// Restore creates the organizations, users and memberships of the snapshot
+// missing in api. Nothing is deleted and the roles of existing members are
+// left unchanged. A failed object does not stop the others; the error is
+// only set when the current state cannot be read.
+func Restore(api RestoreAPI, snapshot Snapshot, opts RestoreOptions) (report Report, err error) {
+	err = snapshot.Validate()
+	if err != nil {
+		return
+	}
+	if opts.Password == nil {
+		opts.Password = randomPassword
+	}
+
+	orgs, err := api.GetOrganizations()
+	if err != nil {
+		return
+	}
+	orgIDs := map[string]string{}
+	for _, org := range orgs {
+		orgIDs[org.Name] = org.OrganizationID
+	}
+	users, err := api.GetUsers()
+	if err != nil {
+		return
+	}
+	logins := map[string]bool{}
+	for _, user := range users {
+		logins[user.Login] = true
+	}
+
+	// organizations missing after the restore, and those created by it
+	missing := map[string]bool{}
+	created := map[string]bool{}
+	for _, org := range snapshot.Organizations {
+		if _, ok := orgIDs[org.Name]; ok {
+			report.add(KindOrganization, org.Name, Skipped, "exists")
+			continue
+		}
+		created[org.Name] = true
+		if opts.DryRun {
+			report.add(KindOrganization, org.Name, Created, "")
+			continue
+		}
+		restored, err := api.CreateOrganization(client.Org{Name: org.Name})
+		if err != nil {
+			missing[org.Name] = true
+			report.add(KindOrganization, org.Name, Failed, err.Error())
+			continue
+		}
+		orgIDs[org.Name] = restored.OrganizationID
+		report.add(KindOrganization, org.Name, Created, "")
+	}
+
+	for _, user := range snapshot.Users {
+		if logins[user.Login] {
+			report.add(KindUser, user.Login, Skipped, "exists")
+			continue
+		}
+		var password string
+		if !opts.DryRun {
+			var err error
+			password, err = opts.Password(user)
+			if err == nil {
+				_, err = api.CreateUser(client.User{Login: user.Login, Email: user.Email, Name: user.Name, Password: password})
+			}
+			if err != nil {
+				report.add(KindUser, user.Login, Failed, err.Error())
+				continue
+			}
+		}
+		logins[user.Login] = true
+		report.add(KindUser, user.Login, Created, "")
+		report.Results[len(report.Results)-1].Password = password
+	}
+
+	for _, org := range snapshot.Organizations {
+		roles := map[string]string{}
+		if missing[org.Name] {
+			for _, member := range org.Members {
+				report.add(KindMember, org.Name+"/"+member.Login, Failed, "organization not restored")
+			}
+			continue
+		}
+		if !created[org.Name] {
+			members, err := api.GetOrganizationUsers(orgIDs[org.Name])
+			if err != nil {
+				return report, fmt.Errorf("organization %s: %v", org.Name, err)
+			}
+			for _, member := range members {
+				roles[member.Login] = member.Role
+			}
+		}
+
+		for _, member := range org.Members {
+			name := org.Name + "/" + member.Login
+			if role, ok := roles[member.Login]; ok {
+				if role == member.Role {
+					report.add(KindMember, name, Skipped, "exists")
+				} else {
+					report.add(KindMember, name, Skipped, fmt.Sprintf("exists as %s, role left unchanged", role))
+				}
+				continue
+			}
+			if !logins[member.Login] {
+				report.add(KindMember, name, Failed, "user not restored")
+				continue
+			}
+			if !opts.DryRun {
+				_, err := api.CreateUserOrganization(orgIDs[org.Name], client.UserInOrganization{Login: member.Login, Role: member.Role})
+				if err != nil {
+					report.add(KindMember, name, Failed, err.Error())
+					continue
+				}
+			}
+			report.add(KindMember, name, Created, "as "+member.Role)
+		}
+	}
+	return
+}
+
+func (r *Report) add(kind Kind, name string, status Status, reason string) {
+	r.Results = append(r.Results, Result{Kind: kind, Name: name, Status: status, Reason: reason})
+}
@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestore(t *testing.T) {
+	source := newTenancy()
+	defer source.Close()
+	snap, _ := Export(source.Client(), Options{})
+
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	main := s.AddOrganization("main")
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com", Name: "Alice"})
+	s.AddMember(main.OrganizationID, alice.UserID, client.RoleViewer)
+
+	report, err := Restore(s.Client(), snap, RestoreOptions{})
+	assert.Equal(t, nil, err)
+	for i, result := range report.Results {
+		if result.Kind == KindUser && result.Status == Created {
+			assert.Len(t, result.Password, 32, "random password reported")
+			report.Results[i].Password = ""
+		}
+	}
+	assert.Equal(t, []Result{
+		{Kind: KindOrganization, Name: "main", Status: Skipped, Reason: "exists"},
+		{Kind: KindOrganization, Name: "team", Status: Created},
+		{Kind: KindUser, Name: "alice", Status: Skipped, Reason: "exists"},
+		{Kind: KindUser, Name: "bob", Status: Created},
+		{Kind: KindUser, Name: "carol", Status: Created},
+		{Kind: KindMember, Name: "main/alice", Status: Skipped, Reason: "exists as Viewer, role left unchanged"},
+		{Kind: KindMember, Name: "team/alice", Status: Created, Reason: "as Admin"},
+		{Kind: KindMember, Name: "team/bob", Status: Created, Reason: "as Viewer"},
+	}, report.Results)
+
+	restored, _ := Export(s.Client(), Options{})
+	snap.Organizations[0].Members[0].Role = client.RoleViewer
+	assert.Equal(t, snap, restored)
+
+	again, err := Restore(s.Client(), snap, RestoreOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(again.Results), again.Count(Skipped), "restore is idempotent")
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	source := newTenancy()
+	defer source.Close()
+	snap, _ := Export(source.Client(), Options{})
+
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	report, err := Restore(s.Client(), snap, RestoreOptions{DryRun: true})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 8, report.Count(Created))
+	assert.Equal(t, []client.Org{}, s.Organizations())
+	assert.Equal(t, []client.User{}, s.Users())
+}
+
+func TestRestoreFailures(t *testing.T) {
+	snap := Snapshot{
+		Version: Version,
+		Organizations: []Organization{
+			{Name: "team", Members: []Member{{Login: "bob", Role: client.RoleViewer}}},
+		},
+		Users: []User{{Login: "bob"}},
+	}
+
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Method: "POST", Path: "/admin/organizations", Status: 500})
+
+	report, err := Restore(s.Client(), snap, RestoreOptions{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Failed, report.Results[0].Status)
+	assert.Equal(t, Created, report.Results[1].Status)
+	assert.Equal(t, Result{Kind: KindMember, Name: "team/bob", Status: Failed, Reason: "organization not restored"}, report.Results[2])
+}
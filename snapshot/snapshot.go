@@ -0,0 +1,262 @@
+// Package snapshot exports the organizations, users and memberships of the
+// visualization API to a versioned document and restores them from it.
+//
+// Documents are deterministic: organizations, users and members are sorted
+// and no IDs or timestamps are written, so two snapshots of the same tenancy
+// are identical and can be diffed or kept in version control. Passwords are
+// not exported; restored users get new ones.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Version of the documents written by this package
+const Version = 1
+
+// Format encoding of a document
+type Format string
+
+// Supported formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// Snapshot document of the tenancy
+type Snapshot struct {
+	Version       int            `json:"version" yaml:"version"`
+	Organizations []Organization `json:"organizations" yaml:"organizations"`
+	Users         []User         `json:"users" yaml:"users"`
+}
+
+// Organization with its members and, when exported, its dashboards
+type Organization struct {
+	Name       string      `json:"name" yaml:"name"`
+	Members    []Member    `json:"members" yaml:"members"`
+	Dashboards []Dashboard `json:"dashboards,omitempty" yaml:"dashboards,omitempty"`
+}
+
+// Member login of a user and its role in the organization
+type Member struct {
+	Login string `json:"login" yaml:"login"`
+	Role  string `json:"role" yaml:"role"`
+}
+
+// User without its ID and password
+type User struct {
+	Login string `json:"login" yaml:"login"`
+	Email string `json:"email" yaml:"email"`
+	Name  string `json:"name" yaml:"name"`
+}
+
+// Dashboard reference of a dashboard of an organization.
+// Dashboards are exported for reference only and are not restored.
+type Dashboard struct {
+	UID    string `json:"uid" yaml:"uid"`
+	Title  string `json:"title" yaml:"title"`
+	Folder string `json:"folder,omitempty" yaml:"folder,omitempty"`
+}
+
+// API is the part of VisualizationClient used by Export
+type API interface {
+	GetOrganizations() ([]client.Org, error)
+	GetUsers() ([]client.User, error)
+	GetOrganizationUsers(ID string) ([]client.UserInOrganization, error)
+}
+
+// DashboardLister lists the dashboards of an organization.
+// The visualization API has no dashboard endpoint, so it is provided by the
+// caller, e.g. backed by the Grafana API.
+type DashboardLister interface {
+	GetOrganizationDashboards(orgID string) ([]Dashboard, error)
+}
+
+// Options of an export
+type Options struct {
+	// Dashboards includes the dashboards of the organizations when set
+	Dashboards DashboardLister
+}
+
+type byOrgName []Organization
+
+func (o byOrgName) Len() int           { return len(o) }
+func (o byOrgName) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o byOrgName) Less(i, j int) bool { return o[i].Name < o[j].Name }
+
+type byUserLogin []User
+
+func (u byUserLogin) Len() int           { return len(u) }
+func (u byUserLogin) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u byUserLogin) Less(i, j int) bool { return u[i].Login < u[j].Login }
+
+type byMemberLogin []Member
+
+func (m byMemberLogin) Len() int           { return len(m) }
+func (m byMemberLogin) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m byMemberLogin) Less(i, j int) bool { return m[i].Login < m[j].Login }
+
+type byDashboardUID []Dashboard
+
+func (d byDashboardUID) Len() int           { return len(d) }
+func (d byDashboardUID) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d byDashboardUID) Less(i, j int) bool { return d[i].UID < d[j].UID }
+
+// Export walks the users, the organizations and their members of api.
+// Members created after the users were listed are left out, so that the
+// snapshot is valid.
+func Export(api API, opts Options) (snapshot Snapshot, err error) {
+	snapshot.Version = Version
+
+	users, err := api.GetUsers()
+	if err != nil {
+		return
+	}
+	snapshot.Users = []User{}
+	logins := map[string]bool{}
+	for _, user := range users {
+		snapshot.Users = append(snapshot.Users, User{Login: user.Login, Email: user.Email, Name: user.Name})
+		logins[user.Login] = true
+	}
+	sort.Sort(byUserLogin(snapshot.Users))
+
+	orgs, err := api.GetOrganizations()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot.Organizations = []Organization{}
+	for _, org := range orgs {
+		members, err := api.GetOrganizationUsers(org.OrganizationID)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("organization %s: %v", org.Name, err)
+		}
+		exported := Organization{Name: org.Name, Members: []Member{}}
+		for _, member := range members {
+			if !logins[member.Login] {
+				continue
+			}
+			exported.Members = append(exported.Members, Member{Login: member.Login, Role: member.Role})
+		}
+		sort.Sort(byMemberLogin(exported.Members))
+
+		if opts.Dashboards != nil {
+			dashboards, err := opts.Dashboards.GetOrganizationDashboards(org.OrganizationID)
+			if err != nil {
+				return Snapshot{}, fmt.Errorf("organization %s: %v", org.Name, err)
+			}
+			exported.Dashboards = append([]Dashboard{}, dashboards...)
+			sort.Sort(byDashboardUID(exported.Dashboards))
+		}
+		snapshot.Organizations = append(snapshot.Organizations, exported)
+	}
+	sort.Sort(byOrgName(snapshot.Organizations))
+
+	err = snapshot.Validate()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return
+}
+
+// Write encodes the snapshot in format, FormatJSON when empty
+func (s Snapshot) Write(w io.Writer, format Format) error {
+	var data []byte
+	var err error
+	switch format {
+	case FormatJSON, "":
+		data, err = json.MarshalIndent(s, "", "  ")
+		data = append(data, '\n')
+	case FormatYAML:
+		data, err = yaml.Marshal(s)
+	default:
+		return fmt.Errorf("unknown snapshot format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Read decodes and validates a snapshot in JSON or YAML
+func Read(r io.Reader) (snapshot Snapshot, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return
+	}
+	err = yaml.UnmarshalStrict(data, &snapshot)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	err = snapshot.Validate()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return
+}
+
+// Validate checks the version and the consistency of the snapshot, and that
+// the client accepts its users and organizations so Restore does not fail
+// row by row
+func (s Snapshot) Validate() error {
+	if s.Version == 0 {
+		return fmt.Errorf("snapshot has no version")
+	}
+	if s.Version > Version {
+		return fmt.Errorf("snapshot version %d is newer than the supported version %d", s.Version, Version)
+	}
+
+	logins := map[string]bool{}
+	for _, user := range s.Users {
+		if user.Login == "" {
+			return fmt.Errorf("user without login")
+		}
+		if logins[user.Login] {
+			return fmt.Errorf("user %s listed twice", user.Login)
+		}
+		logins[user.Login] = true
+		err := client.User{Login: user.Login, Email: user.Email, Name: user.Name}.Validate()
+		if err != nil {
+			return fmt.Errorf("user %s: %v", user.Login, err)
+		}
+	}
+
+	names := map[string]bool{}
+	for _, org := range s.Organizations {
+		if org.Name == "" {
+			return fmt.Errorf("organization without name")
+		}
+		if names[org.Name] {
+			return fmt.Errorf("organization %s listed twice", org.Name)
+		}
+		names[org.Name] = true
+		err := client.Org{Name: org.Name}.Validate()
+		if err != nil {
+			return fmt.Errorf("organization %s: %v", org.Name, err)
+		}
+
+		members := map[string]bool{}
+		for _, member := range org.Members {
+			if !logins[member.Login] {
+				return fmt.Errorf("organization %s: member %s is not a user", org.Name, member.Login)
+			}
+			if members[member.Login] {
+				return fmt.Errorf("organization %s: member %s listed twice", org.Name, member.Login)
+			}
+			members[member.Login] = true
+			switch member.Role {
+			case client.RoleViewer, client.RoleEditor, client.RoleAdmin:
+			default:
+				return fmt.Errorf("organization %s: member %s has invalid role %q", org.Name, member.Login, member.Role)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package snapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// dashboards fake DashboardLister
+type dashboards map[string][]Dashboard
+
+func (d dashboards) GetOrganizationDashboards(orgID string) ([]Dashboard, error) {
+	return d[orgID], nil
+}
+
+func newTenancy() *visualizationtest.Server {
+	s := visualizationtest.NewServer("token")
+	team := s.AddOrganization("team")
+	main := s.AddOrganization("main")
+	bob := s.AddUser(client.User{Login: "bob", Email: "bob@example.com", Name: "Bob"})
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com", Name: "Alice"})
+	s.AddUser(client.User{Login: "carol", Email: "carol@example.com", Name: "Carol"})
+	s.AddMember(team.OrganizationID, bob.UserID, client.RoleViewer)
+	s.AddMember(team.OrganizationID, alice.UserID, client.RoleAdmin)
+	s.AddMember(main.OrganizationID, alice.UserID, client.RoleEditor)
+	return s
+}
+
+func TestExport(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	snap, err := Export(s.Client(), Options{Dashboards: dashboards{
+		"1": {{UID: "b", Title: "Beta"}, {UID: "a", Title: "Alpha", Folder: "ops"}},
+	}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Snapshot{
+		Version: Version,
+		Organizations: []Organization{
+			{Name: "main", Members: []Member{{Login: "alice", Role: client.RoleEditor}}, Dashboards: []Dashboard{}},
+			{
+				Name:       "team",
+				Members:    []Member{{Login: "alice", Role: client.RoleAdmin}, {Login: "bob", Role: client.RoleViewer}},
+				Dashboards: []Dashboard{{UID: "a", Title: "Alpha", Folder: "ops"}, {UID: "b", Title: "Beta"}},
+			},
+		},
+		Users: []User{
+			{Login: "alice", Email: "alice@example.com", Name: "Alice"},
+			{Login: "bob", Email: "bob@example.com", Name: "Bob"},
+			{Login: "carol", Email: "carol@example.com", Name: "Carol"},
+		},
+	}, snap)
+}
+
+// userAddedInBetween adds a member once the users are listed
+type userAddedInBetween struct {
+	*visualizationtest.Server
+}
+
+func (s userAddedInBetween) GetOrganizations() ([]client.Org, error) {
+	return s.Client().GetOrganizations()
+}
+
+func (s userAddedInBetween) GetOrganizationUsers(ID string) ([]client.UserInOrganization, error) {
+	return s.Client().GetOrganizationUsers(ID)
+}
+
+func (s userAddedInBetween) GetUsers() ([]client.User, error) {
+	users, err := s.Client().GetUsers()
+	dave := s.AddUser(client.User{Login: "dave"})
+	s.AddMember("1", dave.UserID, client.RoleViewer)
+	return users, err
+}
+
+func TestExportUserAddedInBetween(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+
+	snap, err := Export(userAddedInBetween{s}, Options{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Member{{Login: "alice", Role: client.RoleAdmin}, {Login: "bob", Role: client.RoleViewer}}, snap.Organizations[1].Members)
+	assert.Len(t, snap.Users, 3)
+}
+
+func TestWriteRead(t *testing.T) {
+	s := newTenancy()
+	defer s.Close()
+	snap, _ := Export(s.Client(), Options{})
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		first, second := &bytes.Buffer{}, &bytes.Buffer{}
+		assert.Equal(t, nil, snap.Write(first, format))
+		again, _ := Export(s.Client(), Options{})
+		again.Write(second, format)
+		assert.Equal(t, first.String(), second.String(), "deterministic %s", format)
+
+		read, err := Read(first)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, snap, read, string(format))
+	}
+
+	out := &bytes.Buffer{}
+	snap.Write(out, FormatYAML)
+	assert.True(t, strings.HasPrefix(out.String(), "version: 1\norganizations:\n- name: main\n  members:\n  - login: alice\n    role: Editor\n"))
+	assert.NotContains(t, out.String(), "dashboards")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{
+			description: "no version",
+			input:       `{"organizations":[],"users":[]}`,
+			expected:    "snapshot has no version",
+		},
+		{
+			description: "newer version",
+			input:       `{"version":2}`,
+			expected:    "snapshot version 2 is newer than the supported version 1",
+		},
+		{
+			description: "unknown member",
+			input:       `{"version":1,"organizations":[{"name":"main","members":[{"login":"bob","role":"Viewer"}]}]}`,
+			expected:    "organization main: member bob is not a user",
+		},
+		{
+			description: "invalid role",
+			input:       "version: 1\nusers: [{login: bob}]\norganizations: [{name: main, members: [{login: bob, role: Owner}]}]",
+			expected:    `organization main: member bob has invalid role "Owner"`,
+		},
+		{
+			description: "invalid email",
+			input:       `{"version":1,"users":[{"login":"bob","email":"bob.example.com"}]}`,
+			expected:    `user bob: invalid payload: email "bob.example.com" is not a valid address`,
+		},
+		{
+			description: "invalid organization name",
+			input:       `{"version":1,"organizations":[{"name":"main "}]}`,
+			expected:    "organization main : invalid payload: name has surrounding spaces",
+		},
+		{
+			description: "unknown field",
+			input:       "version: 1\nteams: []",
+			expected:    "yaml: unmarshal errors:\n  line 2: field teams not found in type snapshot.Snapshot",
+		},
+	}
+	for _, testCase := range tests {
+		_, err := Read(strings.NewReader(testCase.input))
+		assert.Equal(t, testCase.expected, err.Error(), testCase.description)
+	}
+}
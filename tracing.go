@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Attribute keys of the spans opened by the client
+const (
+	AttributeOrgID      = "visualization.org_id"
+	AttributeUserID     = "visualization.user_id"
+	AttributeMethod     = "http.method"
+	AttributeURL        = "http.url"
+	AttributeStatusCode = "http.status_code"
+	AttributeRetryCount = "http.retry_count"
+)
+
+// Attribute annotates a span
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Tracer opens a span for every client call.
+// Package oteltrace implements it with OpenTelemetry.
+type Tracer interface {
+	// Start opens a span as a child of the span in ctx
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+	// Inject writes the trace context of ctx to the header of an outgoing request
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is an operation traced by a Tracer
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// startSpan opens the span of an operation
+func (v *VisualizationClient) startSpan(ctx context.Context, operation string, attributes ...Attribute) (context.Context, Span) {
+	return v.tracer.Start(ctx, operation, attributes...)
+}
+
+// endSpan records err, if any, and ends span
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// noopTracer default Tracer discarding everything
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+func (noopTracer) Inject(ctx context.Context, header http.Header) {}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attributes ...Attribute) {}
+func (noopSpan) RecordError(err error)                 {}
+func (noopSpan) End()                                  {}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+// recordedSpan is a span kept by recordingTracer
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordedSpan) End() {
+	s.ended = true
+}
+
+// path of the span from its root
+func (s *recordedSpan) path() string {
+	if s.parent == nil {
+		return s.name
+	}
+	return s.parent.path() + "/" + s.name
+}
+
+// recordingTracer keeps the spans it opens
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attributes: map[string]interface{}{}}
+	span.SetAttributes(attributes...)
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		header.Set("Traceparent", span.path())
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var traceparents []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+		switch {
+		case r.URL.Path == "/auth/openstack":
+			fmt.Fprintf(w, `{"jwt":"jwt","token":{"expiresAt":"%s"}}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+		case r.Method == "POST":
+			fmt.Fprint(w, `{}`)
+		default:
+			fmt.Fprint(w, `[{"organizationID":"7","name":"team"}]`)
+		}
+	}))
+	defer ts.Close()
+
+	tracer := &recordingTracer{}
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithTracer(tracer))
+	org, err := client.CreateOrganization(Org{Name: "team"})
+	assert.Equal(t, nil, err, "no error")
+	assert.Equal(t, "7", org.OrganizationID)
+
+	var paths []string
+	for _, span := range tracer.spans {
+		paths = append(paths, span.path())
+		assert.True(t, span.ended, span.path())
+	}
+	assert.Equal(t, []string{
+		"CreateOrganization",
+		"CreateOrganization/Authenticate",
+		"CreateOrganization/Authenticate/HTTP POST",
+		"CreateOrganization/HTTP POST",
+		"CreateOrganization/GetOrganizationName",
+		"CreateOrganization/GetOrganizationName/GetOrganizations",
+		"CreateOrganization/GetOrganizationName/GetOrganizations/HTTP GET",
+	}, paths)
+	assert.Equal(t, []string{
+		"CreateOrganization/Authenticate/HTTP POST",
+		"CreateOrganization/HTTP POST",
+		"CreateOrganization/GetOrganizationName/GetOrganizations/HTTP GET",
+	}, traceparents)
+
+	root := tracer.spans[0]
+	assert.Equal(t, "7", root.attributes[AttributeOrgID])
+	attempt := tracer.spans[3]
+	assert.Equal(t, "POST", attempt.attributes[AttributeMethod])
+	assert.Equal(t, ts.URL+"/admin/organizations", attempt.attributes[AttributeURL])
+	assert.Equal(t, 200, attempt.attributes[AttributeStatusCode])
+	assert.Equal(t, 0, attempt.attributes[AttributeRetryCount])
+}
+
+func TestTracingError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt"}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	tracer := &recordingTracer{}
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithTracer(tracer), WithRetries(1))
+	_, err := client.DeleteOrganizationUser("3", "5")
+	assert.NotEqual(t, nil, err)
+
+	root := tracer.spans[0]
+	assert.Equal(t, "DeleteOrganizationUser", root.name)
+	assert.Equal(t, "5", root.attributes[AttributeOrgID])
+	assert.Equal(t, "3", root.attributes[AttributeUserID])
+	assert.Equal(t, err, root.err)
+
+	attempt := tracer.spans[len(tracer.spans)-1]
+	assert.Equal(t, "DeleteOrganizationUser/HTTP DELETE", attempt.path())
+	assert.Equal(t, 404, attempt.attributes[AttributeStatusCode])
+	assert.Equal(t, err, attempt.err)
+}
+
+func TestTracingContext(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/auth/openstack" {
+			fmt.Fprint(w, `{"jwt":"jwt"}`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	}))
+	defer ts.Close()
+
+	tracer := &recordingTracer{}
+	client, _ := NewVisualizationClient(ts.URL, http.Client{}, "token", WithTracer(tracer))
+	ctx, caller := tracer.Start(context.Background(), "caller")
+	_, err := client.GetOrganizationsContext(ctx)
+	caller.End()
+	assert.Equal(t, nil, err, "no error")
+	assert.Equal(t, "caller/GetOrganizations", tracer.spans[1].path(), "child of the span of the caller")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	requests = 0
+	_, err = client.GetOrganizationsContext(ctx)
+	assert.Contains(t, err.Error(), "context canceled")
+	assert.Equal(t, 0, requests, "canceled before sending")
+}
@@ -0,0 +1,506 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StepKind operation completed by a step of a Transaction
+type StepKind string
+
+// Operations a Transaction can compensate
+const (
+	StepCreateOrganization StepKind = "create-organization"
+	StepCreateUser         StepKind = "create-user"
+	StepAddMember          StepKind = "add-member"
+	StepRemoveMember       StepKind = "remove-member"
+)
+
+// Step step of a Transaction, with what its compensation needs
+type Step struct {
+	Kind   StepKind `json:"kind"`
+	OrgID  string   `json:"orgID,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	UserID string   `json:"userID,omitempty"`
+	Login  string   `json:"login,omitempty"`
+	Email  string   `json:"email,omitempty"`
+	Role   string   `json:"role,omitempty"`
+	// Pending the operation was requested but its outcome not recorded yet,
+	// Rollback looks it up to know whether it must be undone
+	Pending bool `json:"pending,omitempty"`
+	// Compensated the step was undone by Rollback
+	Compensated bool `json:"compensated,omitempty"`
+}
+
+// String describes the step on a single line
+func (s Step) String() string {
+	switch s.Kind {
+	case StepCreateOrganization:
+		if s.OrgID == "" {
+			return fmt.Sprintf("%s %s", s.Kind, s.Name)
+		}
+		return fmt.Sprintf("%s %s", s.Kind, s.OrgID)
+	case StepCreateUser:
+		return fmt.Sprintf("%s %s (%s)", s.Kind, s.Login, s.UserID)
+	}
+	return fmt.Sprintf("%s %s/%s %s", s.Kind, s.OrgID, s.Login, s.Role)
+}
+
+// identified reports whether the step has the ID its compensation needs
+func (s Step) identified() bool {
+	if s.Kind == StepCreateOrganization {
+		return s.OrgID != ""
+	}
+	return s.UserID != ""
+}
+
+// Journal steps of a Transaction in order, persisted as JSON to
+// resume or undo the transaction later
+type Journal struct {
+	Steps     []Step `json:"steps"`
+	Committed bool   `json:"committed"`
+}
+
+// RolledBack reports whether every step was compensated
+func (j Journal) RolledBack() bool {
+	for _, step := range j.Steps {
+		if !step.Compensated {
+			return false
+		}
+	}
+	return len(j.Steps) > 0
+}
+
+// Write writes the journal in JSON
+func (j Journal) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j)
+}
+
+// ReadJournal reads a journal written by Journal.Write
+func ReadJournal(r io.Reader) (journal Journal, err error) {
+	err = json.NewDecoder(r).Decode(&journal)
+	return
+}
+
+func (j Journal) copy() Journal {
+	j.Steps = append([]Step{}, j.Steps...)
+	return j
+}
+
+// ErrTransactionDone returned when a committed or rolled back transaction is used
+var ErrTransactionDone = errors.New("transaction already committed or rolled back")
+
+// Transaction runs several operations as a saga: every operation is recorded
+// in a journal as pending before it is requested, and completed with the IDs
+// of what it created once done. Rollback undoes them in reverse order with
+// compensating operations. Safe for concurrent use.
+type Transaction struct {
+	api     Client
+	persist func(Journal) error
+	mu      sync.Mutex
+	journal Journal
+}
+
+// NewTransaction starts a transaction on api.
+// persist, when not nil, is called with the journal after every change:
+// before every operation and after it.
+func NewTransaction(api Client, persist func(Journal) error) *Transaction {
+	return ResumeTransaction(api, Journal{Steps: []Step{}}, persist)
+}
+
+// ResumeTransaction continues a transaction from its persisted journal,
+// to add steps to it, commit it or roll it back
+func ResumeTransaction(api Client, journal Journal, persist func(Journal) error) *Transaction {
+	return &Transaction{api: api, journal: journal.copy(), persist: persist}
+}
+
+// Transact runs fn in a new transaction, committed when fn succeeds and
+// rolled back when it fails. It returns the final journal and the error of fn,
+// along with the rollback error if any.
+func Transact(api Client, persist func(Journal) error, fn func(tx *Transaction) error) (Journal, error) {
+	tx := NewTransaction(api, persist)
+	err := fn(tx)
+	if err != nil {
+		rollbackErr := tx.Rollback()
+		if rollbackErr != nil {
+			err = fmt.Errorf("%v; rollback failed: %v", err, rollbackErr)
+		}
+		return tx.Journal(), err
+	}
+	err = tx.Commit()
+	return tx.Journal(), err
+}
+
+// Journal returns the steps recorded so far
+func (t *Transaction) Journal() Journal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.journal.copy()
+}
+
+// done reports whether the transaction was committed or its rollback started.
+// It must be called with t.mu held.
+func (t *Transaction) done() bool {
+	if t.journal.Committed {
+		return true
+	}
+	for _, step := range t.journal.Steps {
+		if step.Compensated {
+			return true
+		}
+	}
+	return false
+}
+
+// begin records step as pending and persists the journal, before its
+// operation is requested
+func (t *Transaction) begin(step Step) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done() {
+		return ErrTransactionDone
+	}
+	step.Pending = true
+	t.journal.Steps = append(t.journal.Steps, step)
+	err := t.save()
+	if err != nil {
+		t.journal.Steps = t.journal.Steps[:len(t.journal.Steps)-1]
+	}
+	return err
+}
+
+// finish replaces the pending step by its outcome, done or nil when its
+// operation did not happen, and persists the journal
+func (t *Transaction) finish(pending Step, done *Step) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pending.Pending = true
+	for i, step := range t.journal.Steps {
+		if step != pending {
+			continue
+		}
+		if done == nil {
+			t.journal.Steps = append(t.journal.Steps[:i], t.journal.Steps[i+1:]...)
+		} else {
+			done.Pending = false
+			t.journal.Steps[i] = *done
+		}
+		break
+	}
+	return t.save()
+}
+
+// save persists the journal. It must be called with t.mu held.
+func (t *Transaction) save() error {
+	if t.persist == nil {
+		return nil
+	}
+	return t.persist(t.journal.copy())
+}
+
+func (t *Transaction) check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done() {
+		return ErrTransactionDone
+	}
+	return nil
+}
+
+// settle records the outcome of the operation of the pending step, looked up
+// since it may have happened even though it failed or its answer was lost.
+// It returns the step done, or opErr when the operation did not happen.
+func (t *Transaction) settle(pending Step, opErr error) (Step, error) {
+	done, happened, err := t.resolve(pending)
+	if err != nil {
+		// the step stays pending for Rollback to look it up again
+		if opErr != nil {
+			return pending, opErr
+		}
+		return pending, err
+	}
+	if !happened {
+		err = t.finish(pending, nil)
+		if opErr != nil {
+			return pending, opErr
+		}
+		if err != nil {
+			return pending, err
+		}
+		return pending, fmt.Errorf("%s did not happen", pending)
+	}
+	return done, t.finish(pending, &done)
+}
+
+// resolve looks up whether the operation of step happened, and returns the
+// step with the IDs it was missing
+func (t *Transaction) resolve(step Step) (Step, bool, error) {
+	switch step.Kind {
+	case StepCreateOrganization:
+		orgs, err := t.api.GetOrganizations()
+		if err != nil {
+			return step, false, err
+		}
+		for _, org := range orgs {
+			if (step.OrgID != "" && org.OrganizationID == step.OrgID) || (step.OrgID == "" && org.Name == step.Name) {
+				step.OrgID = org.OrganizationID
+				return step, true, nil
+			}
+		}
+		return step, false, nil
+	case StepCreateUser:
+		users, err := t.api.GetUsers()
+		if err != nil {
+			return step, false, err
+		}
+		for _, user := range users {
+			if (step.UserID != "" && user.UserID == step.UserID) || (step.UserID == "" && user.Login == step.Login) {
+				step.UserID = user.UserID
+				return step, true, nil
+			}
+		}
+		return step, false, nil
+	case StepAddMember, StepRemoveMember:
+		if step.UserID == "" && step.Login == "" && step.Email == "" {
+			return step, false, errors.New("no user ID, login or email recorded")
+		}
+		members, err := t.api.GetOrganizationUsers(step.OrgID)
+		if err != nil {
+			return step, false, err
+		}
+		for _, member := range members {
+			if (step.UserID == "" || member.UserID != step.UserID) &&
+				(step.Login == "" || member.Login != step.Login) &&
+				(step.Email == "" || member.Email != step.Email) {
+				continue
+			}
+			if step.Kind == StepRemoveMember {
+				return step, false, nil
+			}
+			step.UserID = member.UserID
+			return step, true, nil
+		}
+		return step, step.Kind == StepRemoveMember, nil
+	}
+	return step, false, fmt.Errorf("unknown step %q", step.Kind)
+}
+
+// conflict returns the error of a creation whose resource already exists
+func conflict(description string) error {
+	return VisualizationError{code: "409", message: "Conflict", description: description}
+}
+
+// CreateOrganization creates a organization, deleted on rollback
+func (t *Transaction) CreateOrganization(org Org) (Org, error) {
+	err := t.check()
+	if err != nil {
+		return Org{}, err
+	}
+	step := Step{Kind: StepCreateOrganization, Name: org.Name}
+	_, exists, err := t.resolve(step)
+	if err != nil {
+		return Org{}, err
+	}
+	if exists {
+		return Org{}, conflict(fmt.Sprintf("organization %s already exists", org.Name))
+	}
+	err = t.begin(step)
+	if err != nil {
+		return Org{}, err
+	}
+	created, err := t.api.CreateOrganization(org)
+	if err != nil || created.OrganizationID == "" {
+		done, err := t.settle(step, err)
+		if err != nil {
+			return Org{}, err
+		}
+		return Org{OrganizationID: done.OrgID, Name: org.Name}, nil
+	}
+	done := step
+	done.OrgID = created.OrganizationID
+	return created, t.finish(step, &done)
+}
+
+// CreateUser creates a user, deleted on rollback.
+// The user created is found by its login.
+func (t *Transaction) CreateUser(user User) (User, error) {
+	err := t.check()
+	if err != nil {
+		return User{}, err
+	}
+	step := Step{Kind: StepCreateUser, Login: user.Login}
+	_, exists, err := t.resolve(step)
+	if err != nil {
+		return User{}, err
+	}
+	if exists {
+		return User{}, conflict(fmt.Sprintf("user %s already exists", user.Login))
+	}
+	err = t.begin(step)
+	if err != nil {
+		return User{}, err
+	}
+	_, err = t.api.CreateUser(user)
+	done, err := t.settle(step, err)
+	if err != nil {
+		return User{}, err
+	}
+	user.UserID = done.UserID
+	user.Password = ""
+	return user, nil
+}
+
+// CreateUserOrganization adds a user to a organization, removed on rollback.
+// The member is found by its UserID, Login or Email when the answer has no
+// userID.
+func (t *Transaction) CreateUserOrganization(OrgID string, user UserInOrganization) (UserInOrganization, error) {
+	err := t.check()
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	step := Step{Kind: StepAddMember, OrgID: OrgID, UserID: user.UserID,
+		Login: user.Login, Email: user.Email, Role: user.Role}
+	_, exists, err := t.resolve(step)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	if exists {
+		name := user.Login
+		if name == "" {
+			name = user.Email
+		}
+		if name == "" {
+			name = user.UserID
+		}
+		return UserInOrganization{}, conflict(fmt.Sprintf("user %s already in organization %s", name, OrgID))
+	}
+	err = t.begin(step)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	added, err := t.api.CreateUserOrganization(OrgID, user)
+	if err != nil || added.UserID == "" {
+		done, err := t.settle(step, err)
+		if err != nil {
+			return UserInOrganization{}, err
+		}
+		if added.UserID == "" {
+			added = UserInOrganization{OrgID: OrgID, Login: user.Login, Email: user.Email, Role: user.Role}
+		}
+		added.UserID = done.UserID
+		return added, nil
+	}
+	done := step
+	done.UserID = added.UserID
+	return added, t.finish(step, &done)
+}
+
+// DeleteOrganizationUser removes a user from a organization, added back with
+// the same role on rollback
+func (t *Transaction) DeleteOrganizationUser(userID string, orgID string) (UserInOrganization, error) {
+	err := t.check()
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	member, err := t.api.GetOrganizationUserID(orgID, userID)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	step := Step{Kind: StepRemoveMember, OrgID: orgID, UserID: userID,
+		Login: member.Login, Email: member.Email, Role: member.Role}
+	err = t.begin(step)
+	if err != nil {
+		return UserInOrganization{}, err
+	}
+	removed, err := t.api.DeleteOrganizationUser(userID, orgID)
+	if err != nil {
+		_, err = t.settle(step, err)
+		if err != nil {
+			return UserInOrganization{}, err
+		}
+		return member, nil
+	}
+	return removed, t.finish(step, &step)
+}
+
+// Commit ends the transaction, keeping every step
+func (t *Transaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done() {
+		return ErrTransactionDone
+	}
+	t.journal.Committed = true
+	return t.save()
+}
+
+// Rollback compensates the steps in reverse order, persisting the journal
+// after each one. It stops at the first failed compensation; calling it
+// again resumes from there. Pending steps, and steps missing an ID, are looked
+// up first: those that did not happen count as compensated. Steps already
+// undone by someone else count as compensated too.
+func (t *Transaction) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.journal.Committed {
+		return ErrTransactionDone
+	}
+
+	for i := len(t.journal.Steps) - 1; i >= 0; i-- {
+		step := t.journal.Steps[i]
+		if step.Compensated {
+			continue
+		}
+		happened := true
+		if step.Pending || !step.identified() {
+			var err error
+			step, happened, err = t.resolve(step)
+			if err != nil {
+				return fmt.Errorf("compensating %s: %v", step, err)
+			}
+			step.Pending = false
+		}
+		if happened {
+			if !step.identified() {
+				return fmt.Errorf("compensating %s: no ID recorded", step)
+			}
+			err := t.compensate(step)
+			if err != nil && !hasCode(err, "404") {
+				return fmt.Errorf("compensating %s: %v", step, err)
+			}
+		}
+		step.Compensated = true
+		t.journal.Steps[i] = step
+		err := t.save()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compensate runs the operation undoing step
+func (t *Transaction) compensate(step Step) (err error) {
+	switch step.Kind {
+	case StepCreateOrganization:
+		_, err = t.api.DeleteOrganization(step.OrgID)
+	case StepCreateUser:
+		_, err = t.api.DeleteUser(step.UserID)
+	case StepAddMember:
+		_, err = t.api.DeleteOrganizationUser(step.UserID, step.OrgID)
+	case StepRemoveMember:
+		_, err = t.api.CreateUserOrganization(step.OrgID, UserInOrganization{OrgID: step.OrgID,
+			UserID: step.UserID, Login: step.Login, Email: step.Email, Role: step.Role})
+		if hasCode(err, "409") {
+			err = nil
+		}
+	default:
+		err = fmt.Errorf("unknown step %q", step.Kind)
+	}
+	return
+}
@@ -0,0 +1,243 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// provision creates the organization tenant with alice as Admin and bob as Viewer
+func provision(tx *client.Transaction) error {
+	org, err := tx.CreateOrganization(client.Org{Name: "tenant"})
+	if err != nil {
+		return err
+	}
+	for _, login := range []string{"alice", "bob"} {
+		_, err = tx.CreateUser(client.User{Login: login, Email: login + "@example.com"})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "alice", Role: client.RoleAdmin})
+	if err != nil {
+		return err
+	}
+	_, err = tx.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "bob", Role: client.RoleViewer})
+	return err
+}
+
+func steps(journal client.Journal) (lines []string) {
+	for _, step := range journal.Steps {
+		line := step.String()
+		if step.Pending {
+			line += " pending"
+		}
+		if step.Compensated {
+			line += " compensated"
+		}
+		lines = append(lines, line)
+	}
+	return
+}
+
+func TestTransact(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+
+	var persisted []client.Journal
+	journal, err := client.Transact(s.Client(), func(j client.Journal) error {
+		persisted = append(persisted, j)
+		return nil
+	}, provision)
+	assert.Equal(t, nil, err)
+	assert.True(t, journal.Committed)
+	assert.Equal(t, []string{
+		"create-organization 1",
+		"create-user alice (2)",
+		"create-user bob (3)",
+		"add-member 1/alice Admin",
+		"add-member 1/bob Viewer",
+	}, steps(journal))
+	assert.Equal(t, 11, len(persisted), "journal persisted before and after every step and on commit")
+	assert.True(t, persisted[0].Steps[0].Pending, "step pending before its operation")
+	assert.Equal(t, journal, persisted[10])
+	assert.Equal(t, 2, len(s.Members("1")))
+
+	tx := client.ResumeTransaction(s.Client(), journal, nil)
+	_, err = tx.CreateUser(client.User{Login: "carol"})
+	assert.Equal(t, client.ErrTransactionDone, err)
+	assert.Equal(t, client.ErrTransactionDone, tx.Rollback())
+}
+
+func TestTransactRollback(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Method: "POST", Path: "/admin/organizations/1/users", Status: http.StatusInternalServerError, Times: 2})
+	s.InjectFault(visualizationtest.Fault{Method: "DELETE", Path: "/admin/users/3", Status: http.StatusInternalServerError, Times: 1})
+
+	journal, err := client.Transact(s.Client(), nil, provision)
+	assert.Contains(t, err.Error(), "rollback failed: compensating create-user bob (3)")
+	assert.Equal(t, []string{
+		"create-organization 1",
+		"create-user alice (2)",
+		"create-user bob (3)",
+	}, steps(journal), "rollback stopped at the failed compensation")
+	assert.False(t, journal.RolledBack())
+
+	tx := client.ResumeTransaction(s.Client(), journal, nil)
+	assert.Equal(t, nil, tx.Rollback(), "rollback resumed")
+	assert.Equal(t, []string{
+		"create-organization 1 compensated",
+		"create-user alice (2) compensated",
+		"create-user bob (3) compensated",
+	}, steps(tx.Journal()))
+	assert.True(t, tx.Journal().RolledBack())
+	assert.Equal(t, []client.Org{}, s.Organizations())
+	assert.Equal(t, []client.User{}, s.Users())
+
+	_, err = tx.CreateOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, client.ErrTransactionDone, err)
+	assert.Equal(t, client.ErrTransactionDone, tx.Commit())
+}
+
+func TestTransactionJournalPersistence(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+
+	// the process running the transaction dies after two steps
+	saved := &bytes.Buffer{}
+	tx := client.NewTransaction(s.Client(), func(j client.Journal) error {
+		saved.Reset()
+		return j.Write(saved)
+	})
+	org, err := tx.CreateOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, nil, err)
+	_, err = tx.CreateUser(client.User{Login: "alice"})
+	assert.Equal(t, nil, err)
+	member := s.AddUser(client.User{Login: "bob"})
+	s.AddMember(org.OrganizationID, member.UserID, client.RoleEditor)
+	_, err = tx.DeleteOrganizationUser(member.UserID, org.OrganizationID)
+	assert.Equal(t, nil, err)
+
+	journal, err := client.ReadJournal(saved)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{
+		"create-organization 1",
+		"create-user alice (2)",
+		"remove-member 1/bob Editor",
+	}, steps(journal))
+
+	// the user was deleted in between, its compensation is not needed
+	s.Client().DeleteUser("2")
+	assert.Equal(t, nil, client.ResumeTransaction(s.Client(), journal, nil).Rollback())
+	assert.Equal(t, []client.Org{}, s.Organizations())
+	assert.Equal(t, []client.User{{UserID: "3", Login: "bob"}}, s.Users())
+}
+
+func TestTransactionRemovedMemberRestored(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com"})
+	s.AddMember(org.OrganizationID, alice.UserID, client.RoleEditor)
+	before := s.Members(org.OrganizationID)
+
+	_, err := client.Transact(s.Client(), nil, func(tx *client.Transaction) error {
+		_, err := tx.DeleteOrganizationUser(alice.UserID, org.OrganizationID)
+		if err != nil {
+			return err
+		}
+		return errors.New("provisioning failed")
+	})
+	assert.Equal(t, "provisioning failed", err.Error())
+	assert.Equal(t, before, s.Members(org.OrganizationID), "member added back with the same role")
+}
+
+func TestTransactionCrashBeforeRecording(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+
+	// the process running the transaction dies once alice is created,
+	// before recording it
+	var saved client.Journal
+	tx := client.NewTransaction(s.Client(), func(j client.Journal) error {
+		if len(j.Steps) > 0 && !j.Steps[len(j.Steps)-1].Pending {
+			return errors.New("killed")
+		}
+		saved = j
+		return nil
+	})
+	_, err := tx.CreateUser(client.User{Login: "alice"})
+	assert.Equal(t, "killed", err.Error())
+	assert.Equal(t, []string{"create-user alice () pending"}, steps(saved))
+	assert.Len(t, s.Users(), 1)
+
+	resumed := client.ResumeTransaction(s.Client(), saved, nil)
+	assert.Equal(t, nil, resumed.Rollback())
+	assert.Equal(t, []string{"create-user alice (1) compensated"}, steps(resumed.Journal()))
+	assert.Equal(t, []client.User{}, s.Users())
+
+	// the operation of a pending step never happened
+	pending := client.Journal{Steps: []client.Step{{Kind: client.StepCreateOrganization, Name: "tenant", Pending: true}}}
+	resumed = client.ResumeTransaction(s.Client(), pending, nil)
+	assert.Equal(t, nil, resumed.Rollback())
+	assert.True(t, resumed.Journal().RolledBack())
+}
+
+func TestTransactionCreateUserSameName(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	s.AddUser(client.User{Login: "ops", Name: "Ops"})
+
+	_, err := client.Transact(s.Client(), nil, func(tx *client.Transaction) error {
+		alice, err := tx.CreateUser(client.User{Login: "alice", Name: "Ops"})
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, "2", alice.UserID, "found by login")
+		_, err = tx.CreateUser(client.User{Login: "ops", Name: "Other"})
+		assert.Equal(t, "ERROR: user ops already exists", err.Error())
+		return err
+	})
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, []client.User{{UserID: "1", Login: "ops", Name: "Ops"}}, s.Users(), "only the created user deleted")
+}
+
+// noMemberID answers the additions of members without their userID, as the
+// API does
+type noMemberID struct {
+	client.Client
+}
+
+func (c noMemberID) CreateUserOrganization(OrgID string, user client.UserInOrganization) (client.UserInOrganization, error) {
+	added, err := c.Client.CreateUserOrganization(OrgID, user)
+	added.UserID = ""
+	return added, err
+}
+
+func TestTransactionMemberWithoutUserID(t *testing.T) {
+	s := visualizationtest.NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	alice := s.AddUser(client.User{Login: "alice", Email: "alice@example.com"})
+
+	journal, err := client.Transact(noMemberID{s.Client()}, nil, func(tx *client.Transaction) error {
+		added, err := tx.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "alice", Role: client.RoleEditor})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, alice.UserID, added.UserID)
+		return errors.New("provisioning failed")
+	})
+	assert.Equal(t, "provisioning failed", err.Error())
+	assert.Equal(t, []string{"add-member 1/alice Editor compensated"}, steps(journal))
+	assert.Len(t, s.Members(org.OrganizationID), 0)
+
+	// a step without any way to find its user is not counted as compensated
+	unknown := client.Journal{Steps: []client.Step{{Kind: client.StepAddMember, OrgID: org.OrganizationID, Role: client.RoleViewer}}}
+	err = client.ResumeTransaction(s.Client(), unknown, nil).Rollback()
+	assert.Equal(t, "compensating add-member 1/ Viewer: no user ID, login or email recorded", err.Error())
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// UserOrganization organization of a user and the role the user has in it
+type UserOrganization struct {
+	OrgID string `json:"orgID"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+}
+
+// userOrgsConcurrency organizations whose users are fetched at a time
+// when the API has no endpoint listing the organizations of a user
+const userOrgsConcurrency = 8
+
+// States of VisualizationClient.userOrgsEndpoint
+const (
+	endpointUnknown int32 = iota
+	endpointAvailable
+	endpointMissing
+)
+
+// missingEndpoint reports whether a status answers a request to an endpoint
+// the API does not serve
+func missingEndpoint(status int) bool {
+	return status == 404 || status == 405 || status == 501
+}
+
+// GetUserOrganizations returns the organizations the user with ID userID
+// belongs to, with the user's role, ordered like GetOrganizations.
+// It asks the API for them when it can, and otherwise gets the users of
+// every organization, several at a time. The client remembers which way works.
+func (v *VisualizationClient) GetUserOrganizations(userID string) (orgs []UserOrganization, err error) {
+	return v.GetUserOrganizationsContext(context.Background(), userID)
+}
+
+// GetUserOrganizationsContext is GetUserOrganizations within ctx
+func (v *VisualizationClient) GetUserOrganizationsContext(ctx context.Context, userID string) (orgs []UserOrganization, err error) {
+	ctx, span := v.startSpan(ctx, "GetUserOrganizations", Attribute{Key: AttributeUserID, Value: userID})
+	defer func() { endSpan(span, err) }()
+
+	if atomic.LoadInt32(&v.userOrgsEndpoint) != endpointMissing {
+		reqURL := fmt.Sprintf("%s/admin/users/%s/organizations", v.url, userID)
+		response, status, err := v.request(ctx, "GetUserOrganizations", "GET", reqURL, nil, false)
+		if err == nil {
+			atomic.StoreInt32(&v.userOrgsEndpoint, endpointAvailable)
+			orgs = []UserOrganization{}
+			err = json.NewDecoder(response).Decode(&orgs)
+			return orgs, err
+		}
+		if !missingEndpoint(status) || atomic.LoadInt32(&v.userOrgsEndpoint) == endpointAvailable {
+			return []UserOrganization{}, err
+		}
+	}
+
+	// the user is looked up first, as the endpoint answers 404 both when it
+	// is missing and when the user is unknown
+	_, err = v.getUserID(ctx, userID)
+	if err != nil {
+		return []UserOrganization{}, err
+	}
+	atomic.StoreInt32(&v.userOrgsEndpoint, endpointMissing)
+	return v.memberOrganizations(ctx, userID)
+}
+
+// memberOrganizations finds the organizations of a user in the users of
+// every organization, fetched userOrgsConcurrency at a time
+func (v *VisualizationClient) memberOrganizations(ctx context.Context, userID string) ([]UserOrganization, error) {
+	all, _, err := v.getOrganizations(ctx)
+	if err != nil {
+		return []UserOrganization{}, err
+	}
+
+	found := make([]*UserOrganization, len(all))
+	errs := make([]error, len(all))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < userOrgsConcurrency && w < len(all); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				members, _, err := v.getOrganizationUsers(ctx, all[i].OrganizationID)
+				// organizations deleted in between are skipped
+				if err != nil && !hasCode(err, "404") {
+					errs[i] = err
+					continue
+				}
+				for _, member := range members {
+					if member.UserID == userID {
+						found[i] = &UserOrganization{OrgID: all[i].OrganizationID, Name: all[i].Name, Role: member.Role}
+					}
+				}
+			}
+		}()
+	}
+	for i := range all {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	orgs := []UserOrganization{}
+	for i := range all {
+		if errs[i] != nil {
+			return []UserOrganization{}, errs[i]
+		}
+		if found[i] != nil {
+			orgs = append(orgs, *found[i])
+		}
+	}
+	return orgs, nil
+}
@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/kbhonagiri16/visualization-client/visualizationtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMemberships returns a server where alice (ID 4) is Editor of alpha and
+// Admin of gamma, and bob (ID 5) Viewer of beta
+func newMemberships() *visualizationtest.Server {
+	s := visualizationtest.NewServer("token")
+	alpha := s.AddOrganization("alpha")
+	beta := s.AddOrganization("beta")
+	gamma := s.AddOrganization("gamma")
+	alice := s.AddUser(client.User{Login: "alice"})
+	bob := s.AddUser(client.User{Login: "bob"})
+	s.AddMember(alpha.OrganizationID, alice.UserID, client.RoleEditor)
+	s.AddMember(beta.OrganizationID, bob.UserID, client.RoleViewer)
+	s.AddMember(gamma.OrganizationID, alice.UserID, client.RoleAdmin)
+	return s
+}
+
+var aliceOrganizations = []client.UserOrganization{
+	{OrgID: "1", Name: "alpha", Role: client.RoleEditor},
+	{OrgID: "3", Name: "gamma", Role: client.RoleAdmin},
+}
+
+func TestGetUserOrganizations(t *testing.T) {
+	s := newMemberships()
+	defer s.Close()
+	api := s.Client()
+
+	orgs, err := api.GetUserOrganizations("4")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, aliceOrganizations, orgs)
+	assert.Equal(t, []string{"POST /auth/openstack", "GET /admin/users/4/organizations"}, s.Requests())
+
+	orgs, err = api.GetUserOrganizations("6")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+	assert.Equal(t, []client.UserOrganization{}, orgs)
+
+	s.AddUser(client.User{Login: "carol"})
+	orgs, err = api.GetUserOrganizations("6")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []client.UserOrganization{}, orgs)
+}
+
+func TestGetUserOrganizationsFallback(t *testing.T) {
+	s := newMemberships()
+	defer s.Close()
+	s.InjectFault(visualizationtest.Fault{Path: "/admin/users/4/organizations", Status: http.StatusNotFound})
+	s.InjectFault(visualizationtest.Fault{Path: "/admin/users/5/organizations", Status: http.StatusNotFound})
+	s.InjectFault(visualizationtest.Fault{Path: "/admin/users/6/organizations", Status: http.StatusNotFound})
+	api := s.Client()
+
+	// an unknown user does not tell whether the endpoint is missing
+	_, err := api.GetUserOrganizations("6")
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error())
+
+	s.ResetRequests()
+	orgs, err := api.GetUserOrganizations("4")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, aliceOrganizations, orgs)
+	requests := s.Requests()
+	assert.Equal(t, []string{"GET /admin/users/4/organizations", "GET /admin/users/4", "GET /admin/organizations"}, requests[:3])
+	fanOut := requests[3:]
+	sort.Strings(fanOut)
+	assert.Equal(t, []string{
+		"GET /admin/organizations/1/users",
+		"GET /admin/organizations/2/users",
+		"GET /admin/organizations/3/users",
+	}, fanOut)
+
+	s.ResetRequests()
+	orgs, err = api.GetUserOrganizations("5")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []client.UserOrganization{{OrgID: "2", Name: "beta", Role: client.RoleViewer}}, orgs)
+	assert.NotContains(t, s.Requests(), "GET /admin/users/5/organizations", "missing endpoint remembered")
+
+	s.InjectFault(visualizationtest.Fault{Path: "/admin/organizations/2/users", Status: http.StatusInternalServerError})
+	orgs, err = api.GetUserOrganizations("4")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, []client.UserOrganization{}, orgs)
+}
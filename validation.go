@@ -0,0 +1,134 @@
+package client
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength longest login, email or name accepted by the API
+const MaxLength = 190
+
+// FieldError invalid field of a payload, named as in its JSON
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Error generate a error message.
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Reason)
+}
+
+// ValidationError invalid fields of a payload, returned by the Validate
+// methods and by the Create* calls before sending anything
+type ValidationError []FieldError
+
+// Error generate a error message.
+func (e ValidationError) Error() string {
+	reasons := make([]string, len(e))
+	for i, field := range e {
+		reasons[i] = field.Error()
+	}
+	return "invalid payload: " + strings.Join(reasons, ", ")
+}
+
+// fieldChecker collects the invalid fields of a payload
+type fieldChecker struct {
+	errs ValidationError
+}
+
+func (v *fieldChecker) fail(field string, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+// err returns the invalid fields, nil when there are none
+func (v *fieldChecker) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *fieldChecker) length(field string, value string) {
+	if utf8.RuneCountInString(value) > MaxLength {
+		v.fail(field, "is longer than %d characters", MaxLength)
+	}
+}
+
+// login checks a login made of letters, digits and . _ - @ +
+func (v *fieldChecker) login(value string) {
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._-@+", r) {
+			v.fail("login", "contains %q, only letters, digits and . _ - @ + are allowed", r)
+			return
+		}
+	}
+	v.length("login", value)
+}
+
+func (v *fieldChecker) email(value string) {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Name != "" || address.Address != value {
+		v.fail("email", "%q is not a valid address", value)
+		return
+	}
+	v.length("email", value)
+}
+
+// Validate checks the user can be created: a login is required, the email
+// must be an address, and the login, email and name at most MaxLength long
+func (u User) Validate() error {
+	v := &fieldChecker{}
+	if u.Login == "" {
+		v.fail("login", "is required")
+	} else {
+		v.login(u.Login)
+	}
+	if u.Email != "" {
+		v.email(u.Email)
+	}
+	v.length("name", u.Name)
+	return v.err()
+}
+
+// Validate checks the organization can be created: a name is required,
+// without surrounding spaces and at most MaxLength long
+func (o Org) Validate() error {
+	v := &fieldChecker{}
+	switch {
+	case o.Name == "":
+		v.fail("name", "is required")
+	case strings.TrimSpace(o.Name) != o.Name:
+		v.fail("name", "has surrounding spaces")
+	default:
+		v.length("name", o.Name)
+	}
+	return v.err()
+}
+
+// Validate checks the user can be added to an organization: the user is
+// identified by userID, login or email, and the role is one of RoleViewer,
+// RoleEditor and RoleAdmin
+func (u UserInOrganization) Validate() error {
+	v := &fieldChecker{}
+	if u.UserID == "" && u.Login == "" && u.Email == "" {
+		v.fail("login", "is required without userID or email")
+	}
+	if u.Login != "" {
+		v.login(u.Login)
+	}
+	if u.Email != "" {
+		v.email(u.Email)
+	}
+	switch u.Role {
+	case RoleViewer, RoleEditor, RoleAdmin:
+	case "":
+		v.fail("role", "is required")
+	default:
+		v.fail("role", "%q is not one of %s, %s, %s", u.Role, RoleViewer, RoleEditor, RoleAdmin)
+	}
+	return v.err()
+}
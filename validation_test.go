@@ -0,0 +1,104 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		user        User
+		expectedErr string
+	}{
+		{"valid", User{Login: "alice", Email: "alice@example.com", Name: "Alice"}, ""},
+		{"login as email", User{Login: "alice+ops@example.com"}, ""},
+		{"no login", User{Email: "alice@example.com"}, "invalid payload: login is required"},
+		{"login with space", User{Login: "alice smith"}, `invalid payload: login contains ' ', only letters, digits and . _ - @ + are allowed`},
+		{"malformed email", User{Login: "alice", Email: "alice@"}, `invalid payload: email "alice@" is not a valid address`},
+		{"email with name", User{Login: "alice", Email: "Alice <alice@example.com>"}, `invalid payload: email "Alice <alice@example.com>" is not a valid address`},
+		{"long name", User{Login: "alice", Name: strings.Repeat("a", MaxLength+1)}, "invalid payload: name is longer than 190 characters"},
+		{"several fields", User{Email: "nope"}, `invalid payload: login is required, email "nope" is not a valid address`},
+	}
+	for _, testCase := range tests {
+		err := testCase.user.Validate()
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+		}
+	}
+
+	err := User{Email: "nope"}.Validate()
+	assert.Equal(t, ValidationError{
+		{Field: "login", Reason: "is required"},
+		{Field: "email", Reason: `"nope" is not a valid address`},
+	}, err, "field-level errors")
+}
+
+func TestOrgValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		org         Org
+		expectedErr string
+	}{
+		{"valid", Org{Name: "tenant a"}, ""},
+		{"no name", Org{}, "invalid payload: name is required"},
+		{"surrounding spaces", Org{Name: "tenant "}, "invalid payload: name has surrounding spaces"},
+		{"long name", Org{Name: strings.Repeat("é", MaxLength+1)}, "invalid payload: name is longer than 190 characters"},
+	}
+	for _, testCase := range tests {
+		err := testCase.org.Validate()
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+		}
+	}
+}
+
+func TestUserInOrganizationValidate(t *testing.T) {
+	tests := []struct {
+		description string
+		user        UserInOrganization
+		expectedErr string
+	}{
+		{"by login", UserInOrganization{Login: "alice", Role: RoleViewer}, ""},
+		{"by ID", UserInOrganization{UserID: "2", Role: RoleAdmin}, ""},
+		{"by email", UserInOrganization{Email: "alice@example.com", Role: RoleEditor}, ""},
+		{"no user", UserInOrganization{Role: RoleViewer}, "invalid payload: login is required without userID or email"},
+		{"no role", UserInOrganization{Login: "alice"}, "invalid payload: role is required"},
+		{"role case and space", UserInOrganization{Login: "alice", Role: "viewer "}, `invalid payload: role "viewer " is not one of Viewer, Editor, Admin`},
+		{"malformed email", UserInOrganization{Email: "alice", Role: RoleViewer}, `invalid payload: email "alice" is not a valid address`},
+	}
+	for _, testCase := range tests {
+		err := testCase.user.Validate()
+		if testCase.expectedErr == "" {
+			assert.Equal(t, nil, err, testCase.description)
+		} else {
+			assert.Equal(t, testCase.expectedErr, err.Error(), testCase.description)
+		}
+	}
+}
+
+func TestCreateValidatesBeforeSending(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+	client, err := NewVisualizationClient(ts.URL, http.Client{}, "token")
+	assert.Equal(t, err, nil, "no error")
+
+	_, err = client.CreateUser(User{Login: ""})
+	assert.Equal(t, ValidationError{{Field: "login", Reason: "is required"}}, err)
+	_, err = client.CreateOrganization(Org{Name: " "})
+	assert.Equal(t, ValidationError{{Field: "name", Reason: "has surrounding spaces"}}, err)
+	_, err = client.CreateUserOrganization("1", UserInOrganization{Login: "alice", Role: "viewer "})
+	assert.Equal(t, ValidationError{{Field: "role", Reason: `"viewer " is not one of Viewer, Editor, Admin`}}, err)
+	assert.Equal(t, 0, requests, "nothing sent")
+}
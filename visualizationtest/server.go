@@ -0,0 +1,591 @@
+// Package visualizationtest provides an in-memory implementation of the
+// visualization API for integration tests.
+//
+// The server keeps real state: organizations, users and memberships get
+// sequential IDs, unknown IDs answer 404, duplicates answer 409 and every
+// /admin request needs the JWT issued by /auth/openstack. Faults can be
+// injected to exercise error handling.
+//
+// GET responses carry an ETag and honour If-None-Match; deletes honour
+// If-Match.
+package visualizationtest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+)
+
+// Fault makes matching requests fail or slow down
+type Fault struct {
+	// Method to match, any method when empty
+	Method string
+	// Path to match exactly, any path when empty
+	Path string
+	// Status answered instead of serving the request, none when zero
+	Status int
+	// Delay before answering
+	Delay time.Duration
+	// Times the fault applies, forever when zero
+	Times int
+}
+
+func (f Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && (f.Path == "" || f.Path == r.URL.Path)
+}
+
+// Server fake visualization API
+type Server struct {
+	*httptest.Server
+
+	// TokenTTL validity of the issued JWTs, one hour by default
+	TokenTTL time.Duration
+
+	mu             sync.Mutex
+	openstackToken string
+	jwts           map[string]time.Time
+	orgs           map[string]client.Org
+	users          map[string]client.User
+	members        map[string]map[string]client.UserInOrganization
+	lastID         int
+	faults         []*Fault
+	requests       []string
+}
+
+// NewServer starts a fake API accepting openstackToken for authentication
+func NewServer(openstackToken string) *Server {
+	s := &Server{
+		TokenTTL:       time.Hour,
+		openstackToken: openstackToken,
+		jwts:           map[string]time.Time{},
+		orgs:           map[string]client.Org{},
+		users:          map[string]client.User{},
+		members:        map[string]map[string]client.UserInOrganization{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client of the server authenticated with its token
+func (s *Server) Client() *client.VisualizationClient {
+	api, _ := client.NewVisualizationClient(s.URL, http.Client{}, s.openstackToken)
+	return api
+}
+
+// InjectFault adds a fault, the first matching fault applies
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// Requests returns the "METHOD /path" of every request served so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ResetRequests forgets the requests served so far
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// AddOrganization creates an organization directly in the server state
+func (s *Server) AddOrganization(name string) client.Org {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := client.Org{OrganizationID: s.nextID(), Name: name}
+	s.orgs[org.OrganizationID] = org
+	s.members[org.OrganizationID] = map[string]client.UserInOrganization{}
+	return org
+}
+
+// AddUser creates a user directly in the server state
+func (s *Server) AddUser(user client.User) client.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user.UserID = s.nextID()
+	s.users[user.UserID] = user
+	return public(user)
+}
+
+// AddMember adds a user to an organization directly in the server state.
+// It fails when the organization or the user does not exist.
+func (s *Server) AddMember(orgID string, userID string, role string) (client.UserInOrganization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.members[orgID] == nil {
+		return client.UserInOrganization{}, fmt.Errorf("organization %s not found", orgID)
+	}
+	user, ok := s.users[userID]
+	if !ok {
+		return client.UserInOrganization{}, fmt.Errorf("user %s not found", userID)
+	}
+	member := membership(orgID, user, role)
+	s.members[orgID][userID] = member
+	return member, nil
+}
+
+// Organizations returns the organizations ordered by ID
+func (s *Server) Organizations() []client.Org {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	orgs := []client.Org{}
+	for _, id := range sortedIDs(s.orgs) {
+		orgs = append(orgs, s.orgs[id])
+	}
+	return orgs
+}
+
+// Users returns the users ordered by ID, without their password
+func (s *Server) Users() []client.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	users := []client.User{}
+	for _, id := range sortedIDs(s.users) {
+		users = append(users, public(s.users[id]))
+	}
+	return users
+}
+
+// Members returns the members of an organization ordered by user ID
+func (s *Server) Members(orgID string) []client.UserInOrganization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	members := []client.UserInOrganization{}
+	for _, id := range sortedIDs(s.members[orgID]) {
+		members = append(members, s.members[orgID][id])
+	}
+	return members
+}
+
+func (s *Server) nextID() string {
+	s.lastID++
+	return strconv.Itoa(s.lastID)
+}
+
+// sortedIDs returns the keys of a map indexed by numeric IDs in numeric order
+func sortedIDs(m interface{}) []string {
+	var ids []int
+	switch m := m.(type) {
+	case map[string]client.Org:
+		for id := range m {
+			n, _ := strconv.Atoi(id)
+			ids = append(ids, n)
+		}
+	case map[string]client.User:
+		for id := range m {
+			n, _ := strconv.Atoi(id)
+			ids = append(ids, n)
+		}
+	case map[string]client.UserInOrganization:
+		for id := range m {
+			n, _ := strconv.Atoi(id)
+			ids = append(ids, n)
+		}
+	}
+	sort.Ints(ids)
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = strconv.Itoa(id)
+	}
+	return keys
+}
+
+func public(user client.User) client.User {
+	user.Password = ""
+	return user
+}
+
+func membership(orgID string, user client.User, role string) client.UserInOrganization {
+	return client.UserInOrganization{OrgID: orgID, UserID: user.UserID, Login: user.Login,
+		Email: user.Email, Role: role}
+}
+
+// fault returns the fault applying to the request and consumes one use of it
+func (s *Server) fault(r *http.Request) (fault Fault, found bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return *f, true
+	}
+	return
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// etag returns the entity tag of the JSON representation of value
+func etag(value interface{}) string {
+	data, _ := json.Marshal(value)
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// writeRepresentation answers a GET with value and its ETag, or with 304 when
+// the client already has it
+func writeRepresentation(w http.ResponseWriter, r *http.Request, value interface{}) {
+	tag := etag(value)
+	w.Header().Set("ETag", tag)
+	if r.Header.Get("If-None-Match") == tag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, value)
+}
+
+// preconditionFailed answers 412 when the If-Match header of r does not match value
+func preconditionFailed(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" || match == "*" || match == etag(value) {
+		return false
+	}
+	writeError(w, http.StatusPreconditionFailed, "resource was modified")
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	if fault, found := s.fault(r); found {
+		time.Sleep(fault.Delay)
+		if fault.Status != 0 {
+			writeError(w, fault.Status, "injected fault")
+			return
+		}
+	}
+
+	if r.URL.Path == "/auth/openstack" {
+		s.authenticate(w, r)
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired token")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "admin" {
+		writeError(w, http.StatusNotFound, "unknown endpoint")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case parts[1] == "users" && len(parts) == 2:
+		s.serveUsers(w, r)
+	case parts[1] == "users" && len(parts) == 3:
+		s.serveUser(w, r, parts[2])
+	case parts[1] == "users" && len(parts) == 4 && parts[3] == "organizations":
+		s.serveUserOrganizations(w, r, parts[2])
+	case parts[1] == "organizations" && len(parts) == 2:
+		s.serveOrganizations(w, r)
+	case parts[1] == "organizations" && len(parts) == 3:
+		s.serveOrganization(w, r, parts[2])
+	case parts[1] == "organizations" && len(parts) == 4 && parts[3] == "users":
+		s.serveMembers(w, r, parts[2])
+	case parts[1] == "organizations" && len(parts) == 5 && parts[3] == "users":
+		s.serveMember(w, r, parts[2], parts[4])
+	default:
+		writeError(w, http.StatusNotFound, "unknown endpoint")
+	}
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if r.Header.Get("X-OpenStack-Auth-Token") != s.openstackToken {
+		writeError(w, http.StatusUnauthorized, "invalid OpenStack token")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := client.AuthToken{
+		JWT: fmt.Sprintf("jwt-%d", len(s.jwts)+1),
+		Token: client.Token{
+			OrganizationID: "1",
+			ExpiresAt:      time.Now().Add(s.TokenTTL).UTC(),
+			IsAdmin:        true,
+		},
+	}
+	s.jwts[token.JWT] = token.Token.ExpiresAt
+	writeJSON(w, http.StatusOK, token)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.jwts[jwt]
+	return ok && time.Now().Before(expires)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(value)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveUsers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		users := []client.User{}
+		for _, id := range sortedIDs(s.users) {
+			users = append(users, public(s.users[id]))
+		}
+		writeRepresentation(w, r, users)
+
+	case "POST":
+		var user client.User
+		if !decode(w, r, &user) {
+			return
+		}
+		if user.Login == "" {
+			writeError(w, http.StatusBadRequest, "login is required")
+			return
+		}
+		for _, known := range s.users {
+			if known.Login == user.Login || (user.Email != "" && known.Email == user.Email) {
+				writeError(w, http.StatusConflict, "user already exists")
+				return
+			}
+		}
+		user.UserID = s.nextID()
+		user.OrgID = ""
+		s.users[user.UserID] = user
+		writeJSON(w, http.StatusOK, public(user))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveUserOrganizations(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := s.users[id]; !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	orgs := []client.UserOrganization{}
+	for _, orgID := range sortedIDs(s.orgs) {
+		if member, ok := s.members[orgID][id]; ok {
+			orgs = append(orgs, client.UserOrganization{OrgID: orgID, Name: s.orgs[orgID].Name, Role: member.Role})
+		}
+	}
+	writeRepresentation(w, r, orgs)
+}
+
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request, id string) {
+	user, ok := s.users[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeRepresentation(w, r, public(user))
+
+	case "DELETE":
+		if preconditionFailed(w, r, public(user)) {
+			return
+		}
+		delete(s.users, id)
+		for _, members := range s.members {
+			delete(members, id)
+		}
+		writeJSON(w, http.StatusOK, public(user))
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveOrganizations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		orgs := []client.Org{}
+		for _, id := range sortedIDs(s.orgs) {
+			orgs = append(orgs, s.orgs[id])
+		}
+		writeRepresentation(w, r, orgs)
+
+	case "POST":
+		var org client.Org
+		if !decode(w, r, &org) {
+			return
+		}
+		if org.Name == "" {
+			writeError(w, http.StatusBadRequest, "name is required")
+			return
+		}
+		for _, known := range s.orgs {
+			if known.Name == org.Name {
+				writeError(w, http.StatusConflict, "organization already exists")
+				return
+			}
+		}
+		org.OrganizationID = s.nextID()
+		s.orgs[org.OrganizationID] = org
+		s.members[org.OrganizationID] = map[string]client.UserInOrganization{}
+		writeJSON(w, http.StatusOK, org)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveOrganization(w http.ResponseWriter, r *http.Request, id string) {
+	org, ok := s.orgs[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeRepresentation(w, r, org)
+
+	case "DELETE":
+		if preconditionFailed(w, r, org) {
+			return
+		}
+		delete(s.orgs, id)
+		delete(s.members, id)
+		writeJSON(w, http.StatusOK, org)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveMembers(w http.ResponseWriter, r *http.Request, orgID string) {
+	members, ok := s.members[orgID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		list := []client.UserInOrganization{}
+		for _, id := range sortedIDs(members) {
+			list = append(list, members[id])
+		}
+		writeRepresentation(w, r, list)
+
+	case "POST":
+		var request client.UserInOrganization
+		if !decode(w, r, &request) {
+			return
+		}
+		switch request.Role {
+		case client.RoleViewer, client.RoleEditor, client.RoleAdmin:
+		default:
+			writeError(w, http.StatusBadRequest, "invalid role")
+			return
+		}
+
+		var user client.User
+		found := false
+		for _, known := range s.users {
+			if (request.UserID != "" && known.UserID == request.UserID) ||
+				(request.Login != "" && known.Login == request.Login) ||
+				(request.Email != "" && known.Email == request.Email) {
+				user, found = known, true
+				break
+			}
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		if _, exists := members[user.UserID]; exists {
+			writeError(w, http.StatusConflict, "user already in organization")
+			return
+		}
+
+		member := membership(orgID, user, request.Role)
+		members[user.UserID] = member
+		writeJSON(w, http.StatusOK, member)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveMember(w http.ResponseWriter, r *http.Request, orgID string, userID string) {
+	member, ok := s.members[orgID][userID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "user not in organization")
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeRepresentation(w, r, member)
+
+	case "DELETE":
+		if preconditionFailed(w, r, member) {
+			return
+		}
+		delete(s.members[orgID], userID)
+		writeJSON(w, http.StatusOK, member)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
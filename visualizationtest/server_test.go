@@ -0,0 +1,134 @@
+package visualizationtest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	client "github.com/kbhonagiri16/visualization-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthentication(t *testing.T) {
+	s := NewServer("token")
+	defer s.Close()
+
+	api, _ := client.NewVisualizationClient(s.URL, http.Client{}, "wrong")
+	_, err := api.Authenticate()
+	assert.NotNil(t, err, "wrong OpenStack token rejected")
+
+	token, err := s.Client().Authenticate()
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, "jwt-1", token.JWT)
+	assert.True(t, token.Token.ExpiresAt.After(time.Now()))
+
+	request, _ := http.NewRequest("GET", s.URL+"/admin/users", nil)
+	response, err := http.DefaultClient.Do(request)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode, "admin endpoints need a JWT")
+}
+
+func TestState(t *testing.T) {
+	s := NewServer("token")
+	defer s.Close()
+	api := s.Client()
+
+	org, err := api.CreateOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, client.Org{OrganizationID: "1", Name: "tenant"}, org)
+
+	_, err = api.CreateOrganization(client.Org{Name: "tenant"})
+	assert.Equal(t, "ERROR: Provided Details to create exists", err.Error(), "duplicate organization")
+
+	user, err := api.CreateUser(client.User{Login: "alice", Name: "Alice", Email: "alice@example.com", Password: "secret"})
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, client.User{UserID: "2", Login: "alice", Name: "Alice", Email: "alice@example.com"}, user)
+
+	member, err := api.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "alice", Role: "Editor"})
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, client.UserInOrganization{OrgID: "1", UserID: "2", Login: "alice", Email: "alice@example.com", Role: "Editor"}, member)
+	assert.Equal(t, []client.UserInOrganization{member}, s.Members("1"))
+
+	_, err = api.CreateUserOrganization(org.OrganizationID, client.UserInOrganization{Login: "bob", Role: "Editor"})
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error(), "unknown user")
+
+	_, err = api.DeleteUser(user.UserID)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, []client.UserInOrganization{}, s.Members("1"), "memberships removed with the user")
+
+	_, err = api.GetUserID(user.UserID)
+	assert.Equal(t, "ERROR: Provided ID to Delete/Get was not found", err.Error(), "deleted user")
+}
+
+func TestAddMember(t *testing.T) {
+	s := NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	user := s.AddUser(client.User{Login: "alice"})
+
+	member, err := s.AddMember(org.OrganizationID, user.UserID, client.RoleViewer)
+	assert.Equal(t, err, nil, "no error")
+	assert.Equal(t, []client.UserInOrganization{member}, s.Members(org.OrganizationID))
+
+	_, err = s.AddMember("9", user.UserID, client.RoleViewer)
+	assert.Equal(t, "organization 9 not found", err.Error())
+	_, err = s.AddMember(org.OrganizationID, "9", client.RoleViewer)
+	assert.Equal(t, "user 9 not found", err.Error())
+}
+
+func TestFaults(t *testing.T) {
+	s := NewServer("token")
+	defer s.Close()
+	s.AddOrganization("tenant")
+	api := s.Client()
+
+	s.InjectFault(Fault{Method: "GET", Path: "/admin/organizations", Status: http.StatusNotFound, Times: 1})
+	_, err := api.GetOrganizations()
+	assert.NotNil(t, err, "fault applied")
+
+	orgs, err := api.GetOrganizations()
+	assert.Equal(t, err, nil, "fault consumed")
+	assert.Equal(t, 1, len(orgs))
+
+	s.InjectFault(Fault{Path: "/admin/organizations/1", Status: http.StatusConflict})
+	_, err = api.GetOrganizationID("1")
+	assert.NotNil(t, err, "fault applied")
+	_, err = api.GetOrganizationID("1")
+	assert.NotNil(t, err, "fault applies forever")
+
+	s.ClearFaults()
+	_, err = api.GetOrganizationID("1")
+	assert.Equal(t, err, nil, "faults cleared")
+}
+
+func TestConditionalRequests(t *testing.T) {
+	s := NewServer("token")
+	defer s.Close()
+	org := s.AddOrganization("tenant")
+	token, _ := s.Client().Authenticate()
+
+	do := func(method string, path string, header string, value string) *http.Response {
+		request, _ := http.NewRequest(method, s.URL+path, nil)
+		request.Header.Set("Authorization", "Bearer "+token.JWT)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		assert.Equal(t, err, nil, "no error")
+		response.Body.Close()
+		return response
+	}
+
+	response := do("GET", "/admin/organizations", "", "")
+	tag := response.Header.Get("ETag")
+	assert.NotEqual(t, "", tag)
+	assert.Equal(t, http.StatusNotModified, do("GET", "/admin/organizations", "If-None-Match", tag).StatusCode)
+
+	s.AddOrganization("other")
+	assert.Equal(t, http.StatusOK, do("GET", "/admin/organizations", "If-None-Match", tag).StatusCode, "list changed")
+
+	path := "/admin/organizations/" + org.OrganizationID
+	assert.Equal(t, http.StatusPreconditionFailed, do("DELETE", path, "If-Match", `"stale"`).StatusCode)
+	tag = do("GET", path, "", "").Header.Get("ETag")
+	assert.Equal(t, http.StatusOK, do("DELETE", path, "If-Match", tag).StatusCode)
+}